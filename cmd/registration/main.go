@@ -2,10 +2,15 @@ package main
 
 import (
 	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
 	"github.com/salex-org/hmip-go-client/pkg/hmip"
+	"io"
 	"os"
 	"strings"
+	"time"
 )
 
 const (
@@ -13,36 +18,191 @@ const (
 	ColorRedBold   = "\033[1;31m"
 	ColorGreenBold = "\033[1;32m"
 	ColorOff       = "\033[0m"
+
+	EnvVarNameWaitTimeout = "HMIP_WAIT_TIMEOUT"
+	EnvVarNameOutput      = "HMIP_OUTPUT"
+
+	ExitCodeConfig             = 1
+	ExitCodeInput              = 2
+	ExitCodeLookup             = 3
+	ExitCodeConnectionRequest  = 4
+	ExitCodeAcknowledge        = 5
+	ExitCodeAuthToken          = 6
+	ExitCodeConfirmAuthToken   = 7
+	ExitCodeOutput             = 8
+	ExitCodeRegistrationFailed = 9
 )
 
+type credentials struct {
+	AccessPointSGTIN hmip.SGTIN `json:"accessPointSgtin"`
+	ClientName       string     `json:"clientName"`
+	DeviceID         string     `json:"deviceId"`
+	ClientID         string     `json:"clientId"`
+	ClientAuthToken  string     `json:"clientAuthToken"`
+	AuthToken        string     `json:"authToken"`
+}
+
 func main() {
 	config, err := hmip.GetConfig()
 	if err != nil {
-		fmt.Printf("\U0001F6AB %sFailed%s to create new client config: %v\n", ColorRedBold, ColorOff, err)
-		return
+		fmt.Fprintf(os.Stderr, "\U0001F6AB %sFailed%s to create new client config: %v\n", ColorRedBold, ColorOff, err)
+		os.Exit(ExitCodeConfig)
 	}
 
-	fmt.Printf("Registering new client in the Homematic IP Cloud\n")
-	fmt.Printf("\U0000251C Client Name: ")
-	config.ClientName = commandLineInput()
-	fmt.Printf("\U0000251C Access Point SGTIN: ")
-	config.AccessPointSGTIN = commandLineInput()
-	fmt.Printf("\U00002570 PIN: ")
-	config.PIN = commandLineInput()
+	config.AcknowledgeTimeout, err = durationFromEnv(EnvVarNameWaitTimeout)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "\U0001F6AB %sFailed%s to read %s: %v\n", ColorRedBold, ColorOff, EnvVarNameWaitTimeout, err)
+		os.Exit(ExitCodeConfig)
+	}
+
+	flags := flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+	flags.StringVar(&config.ClientName, "client-name", config.ClientName, "name of the new client (env "+hmip.EnvVarNameClientName+")")
+	flags.Var(&config.AccessPointSGTIN, "sgtin", "SGTIN of the access point (env "+hmip.EnvVarNameAccessPointSGTIN+")")
+	flags.StringVar(&config.PIN, "pin", config.PIN, "PIN of the access point, if set (env "+hmip.EnvVarNamePIN+")")
+	flags.DurationVar(&config.AcknowledgeTimeout, "wait-timeout", config.AcknowledgeTimeout, "maximum time to wait for the blue button to be pressed, e.g. 2m (env "+EnvVarNameWaitTimeout+")")
+	output := flags.String("output", os.Getenv(EnvVarNameOutput), "file to write the credentials to instead of stdout (env "+EnvVarNameOutput+")")
+	jsonOutput := flags.Bool("json", false, "print the credentials as JSON")
+	nonInteractive := flags.Bool("non-interactive", false, "fail instead of asking for missing inputs")
+	err = flags.Parse(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	if err != nil {
+		os.Exit(ExitCodeConfig)
+	}
+	// The SGTIN from the environment is only validated when it is not overridden by the flag
+	if config.AccessPointSGTIN != "" {
+		err = config.AccessPointSGTIN.Set(string(config.AccessPointSGTIN))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "\U0001F6AB %sFailed%s to read access point SGTIN: %v\n", ColorRedBold, ColorOff, err)
+			os.Exit(ExitCodeConfig)
+		}
+	}
+
+	// All messages are written to stderr when the credentials are printed as JSON
+	log := os.Stdout
+	if *jsonOutput || *output != "" {
+		log = os.Stderr
+	}
+
+	fmt.Fprintf(log, "Registering new client in the Homematic IP Cloud\n")
+	if config.ClientName == "" {
+		config.ClientName, err = requireInput(log, "\U0000251C Client Name: ", *nonInteractive)
+		if err != nil {
+			failInput(log, "client name", err)
+		}
+	}
+	if config.AccessPointSGTIN == "" {
+		var input string
+		input, err = requireInput(log, "\U0000251C Access Point SGTIN: ", *nonInteractive)
+		if err == nil {
+			err = config.AccessPointSGTIN.Set(input)
+		}
+		if err != nil {
+			failInput(log, "access point SGTIN", err)
+		}
+	}
+	if config.PIN == "" && !*nonInteractive {
+		fmt.Fprintf(log, "\U00002570 PIN: ")
+		config.PIN = commandLineInput()
+	}
 
 	err = config.RegisterClient(func() {
-		fmt.Printf("\U0001F6CE Please press the %sblue button%s on the access point to confirm the client registration\n", ColorCyanBold, ColorOff)
+		fmt.Fprintf(log, "\U0001F6CE Please press the %sblue button%s on the access point to confirm the client registration\n", ColorCyanBold, ColorOff)
 	})
 	if err != nil {
-		fmt.Printf("\U0001F6AB %sFailed%s to register new client %s%s%s: %v\n", ColorRedBold, ColorOff, ColorCyanBold, config.ClientName, ColorOff, err)
-		return
+		fmt.Fprintf(log, "\U0001F6AB %sFailed%s to register new client %s%s%s: %v\n", ColorRedBold, ColorOff, ColorCyanBold, config.ClientName, ColorOff, err)
+		os.Exit(exitCode(err))
 	}
+	fmt.Fprintf(log, "\U0001F3C1 %sSuccessfully%s registered new client %s%s%s at access point %s\n", ColorGreenBold, ColorOff, ColorCyanBold, config.ClientName, ColorOff, config.AccessPointSGTIN.Pretty())
 
-	fmt.Printf("\U0001F3C1 %sSuccessfully%s registered new client %s%s%s\n", ColorGreenBold, ColorOff, ColorCyanBold, config.ClientName, ColorOff)
-	fmt.Printf("\U0001F3AB Device ID: %s\n", config.DeviceID)
-	fmt.Printf("\U0001F3AB Client ID: %s\n", config.ClientID)
-	fmt.Printf("\U0001F511 Client Auth Token: %s\n", config.ClientAuthToken)
-	fmt.Printf("\U0001F511 Auth Token: %s\n", config.AuthToken)
+	result := credentials{
+		AccessPointSGTIN: config.AccessPointSGTIN.Canonical(),
+		ClientName:       config.ClientName,
+		DeviceID:         config.DeviceID,
+		ClientID:         config.ClientID,
+		ClientAuthToken:  config.ClientAuthToken,
+		AuthToken:        config.AuthToken,
+	}
+	err = writeCredentials(result, *output, *jsonOutput)
+	if err != nil {
+		fmt.Fprintf(log, "\U0001F6AB %sFailed%s to write credentials: %v\n", ColorRedBold, ColorOff, err)
+		os.Exit(ExitCodeOutput)
+	}
+}
+
+func writeCredentials(result credentials, output string, jsonOutput bool) error {
+	var writer io.Writer = os.Stdout
+	if output != "" {
+		// The credentials contain secrets, so the file is only readable by the owner. The mode
+		// passed to OpenFile applies to new files only, existing files are restricted before writing.
+		file, err := os.OpenFile(output, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+		if err != nil {
+			return err
+		}
+		defer func(file *os.File) {
+			_ = file.Close()
+		}(file)
+		if err := file.Chmod(0600); err != nil {
+			return err
+		}
+		writer = file
+		jsonOutput = true
+	}
+	if jsonOutput {
+		encoder := json.NewEncoder(writer)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(result)
+	}
+	_, err := fmt.Fprintf(writer, "\U0001F3AB Device ID: %s\n\U0001F3AB Client ID: %s\n\U0001F511 Client Auth Token: %s\n\U0001F511 Auth Token: %s\n",
+		result.DeviceID, result.ClientID, result.ClientAuthToken, result.AuthToken)
+	return err
+}
+
+func exitCode(err error) int {
+	var registrationErr *hmip.RegistrationError
+	if !errors.As(err, &registrationErr) {
+		return ExitCodeRegistrationFailed
+	}
+	switch registrationErr.Step {
+	case hmip.REGISTRATION_STEP_LOOKUP:
+		return ExitCodeLookup
+	case hmip.REGISTRATION_STEP_CONNECTION_REQUEST:
+		return ExitCodeConnectionRequest
+	case hmip.REGISTRATION_STEP_ACKNOWLEDGE:
+		return ExitCodeAcknowledge
+	case hmip.REGISTRATION_STEP_AUTH_TOKEN:
+		return ExitCodeAuthToken
+	case hmip.REGISTRATION_STEP_CONFIRM_AUTH_TOKEN:
+		return ExitCodeConfirmAuthToken
+	default:
+		return ExitCodeRegistrationFailed
+	}
+}
+
+func requireInput(log io.Writer, prompt string, nonInteractive bool) (string, error) {
+	if nonInteractive {
+		return "", errors.New("missing value")
+	}
+	fmt.Fprint(log, prompt)
+	input := commandLineInput()
+	if input == "" {
+		return "", errors.New("empty value")
+	}
+	return input, nil
+}
+
+func failInput(log io.Writer, name string, err error) {
+	fmt.Fprintf(log, "\U0001F6AB %sFailed%s to read %s: %v\n", ColorRedBold, ColorOff, name, err)
+	os.Exit(ExitCodeInput)
+}
+
+func durationFromEnv(name string) (time.Duration, error) {
+	value := os.Getenv(name)
+	if value == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(value)
 }
 
 func commandLineInput() string {
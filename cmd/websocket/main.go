@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/salex-org/hmip-go-client/pkg/hmip"
 	"log"
@@ -36,7 +37,11 @@ func main() {
 
 	go func() {
 		defer wait.Done()
-		_ = client.ListenForEvents()
+		err := client.ListenForEvents()
+		if errors.Is(err, hmip.ErrClientRevoked) {
+			fmt.Printf("\U0001F6AB %sRevoked%s client, please register a new client\n", ColorRedBold, ColorOff)
+			stop()
+		}
 	}()
 
 	// Shutdown function waiting for the SIGTERM notification to start the shutdown process
@@ -59,8 +64,12 @@ func LoggingGenericEventHandler(event hmip.Event, origin hmip.Origin) {
 	switch specialEvent := event.(type) {
 	case hmip.DeviceChangedEvent:
 		device := specialEvent.GetDevice()
+		if device == nil {
+			fmt.Printf("\n")
+			return
+		}
 		channels := device.GetFunctionalChannels()
-		fmt.Printf(" for device %s (type %s)\n", device.GetName(), device.GetType())
+		fmt.Printf(" for device %s (type %s, SGTIN %s)\n", device.GetName(), device.GetType(), device.GetSGTIN().Pretty())
 		for _, channel := range channels {
 			switch specialChannel := channel.(type) {
 			case hmip.SwitchChannel:
@@ -77,7 +86,19 @@ func LoggingGenericEventHandler(event hmip.Event, origin hmip.Origin) {
 			}
 		}
 	case hmip.GroupChangedEvent:
-		fmt.Printf(" for group %s (type %s)\n", specialEvent.GetGroup().GetName(), specialEvent.GetGroup().GetType())
+		group := specialEvent.GetGroup()
+		if group == nil {
+			fmt.Printf("\n")
+			return
+		}
+		fmt.Printf(" for group %s (type %s)\n", group.GetName(), group.GetType())
+	case hmip.HomeChangedEvent:
+		home := specialEvent.GetHome()
+		if home == nil {
+			fmt.Printf("\n")
+			return
+		}
+		fmt.Printf(" for home (connected %t, duty cycle %.1f%%, carrier sense %.1f%%)\n", home.IsConnected(), home.GetDutyCycle(), home.GetCarrierSense())
 	default:
 		fmt.Printf("\n")
 	}
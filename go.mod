@@ -8,4 +8,4 @@ require (
 	github.com/opencontainers/go-digest v1.0.0
 )
 
-require golang.org/x/net v0.19.0 // indirect
+require golang.org/x/net v0.19.0
@@ -0,0 +1,316 @@
+package hmip
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+// The benchmarks decode a synthetic installation of 150 devices and a stream of push messages
+// with 5 events each. The EncodingJSON variants decode the same data the way it was decoded
+// before the single pass decoder, so both can be compared with
+//
+//	go test -run '^$' -bench . ./pkg/hmip
+const (
+	benchmarkDevices       = 150
+	benchmarkMessages      = 100
+	benchmarkEventsPerPush = 5
+)
+
+func BenchmarkParseState(b *testing.B) {
+	data := syntheticStateJSON(b)
+	b.ReportAllocs()
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := ParseState(data)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParseStateEncodingJSON(b *testing.B) {
+	data := syntheticStateJSON(b)
+	b.ReportAllocs()
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := decodeStateByEncodingJSON(bytes.Clone(data))
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkReadState(b *testing.B) {
+	data := syntheticStateJSON(b)
+	b.ReportAllocs()
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := ReadState(bytes.NewReader(data))
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParseStateFixture(b *testing.B) {
+	data := readFixture(b, "state.json")
+	b.ReportAllocs()
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := ParseState(data)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecodePushMessage(b *testing.B) {
+	messages := syntheticPushMessagesJSON(b)
+	// decodePushMessage owns the data, like the messages received by the event loop
+	copies := make([][]byte, len(messages))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		message := i % len(messages)
+		b.StopTimer()
+		copies[message] = append(copies[message][:0], messages[message]...)
+		b.StartTimer()
+		_, _, err := decodePushMessage(copies[message], DecodeOptions{})
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecodePushMessageEncodingJSON(b *testing.B) {
+	messages := syntheticPushMessagesJSON(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		err := decodePushMessageByEncodingJSON(bytes.Clone(messages[i%len(messages)]))
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// decodePushMessageByEncodingJSON decodes the DEVICE_CHANGED events of the synthetic push messages
+// with a first pass for the event type and a second pass for the device.
+func decodePushMessageByEncodingJSON(data []byte) error {
+	var message struct {
+		Events map[string]json.RawMessage `json:"events"`
+		Origin origin                     `json:"origin"`
+	}
+	err := json.Unmarshal(data, &message)
+	if err != nil {
+		return err
+	}
+	for _, value := range message.Events {
+		var event struct {
+			PushEventType string          `json:"pushEventType"`
+			Device        json.RawMessage `json:"device"`
+		}
+		err = json.Unmarshal(value, &event)
+		if err != nil {
+			return err
+		}
+		_, err = decodeDeviceByEncodingJSON(event.Device)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ======================================================
+
+type object map[string]any
+
+func syntheticStateJSON(b *testing.B) []byte {
+	data, err := json.Marshal(syntheticState(benchmarkDevices))
+	if err != nil {
+		b.Fatal(err)
+	}
+	return data
+}
+
+func syntheticPushMessagesJSON(b *testing.B) [][]byte {
+	messages := make([][]byte, benchmarkMessages)
+	for i := range messages {
+		var err error
+		messages[i], err = json.Marshal(syntheticPushMessage(benchmarkDevices, benchmarkEventsPerPush, i))
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+	return messages
+}
+
+func syntheticDeviceID(i int) string {
+	return fmt.Sprintf("3014F711A0000%011X", i)
+}
+
+func syntheticState(deviceCount int) object {
+	devices := make(object, deviceCount)
+	for i := 0; i < deviceCount; i++ {
+		devices[syntheticDeviceID(i)] = syntheticDevice(i, int64(i))
+	}
+	groups := make(object)
+	for room := 0; room*5 < deviceCount; room++ {
+		metaID := fmt.Sprintf("meta-%d", room)
+		heatingID := fmt.Sprintf("heating-%d", room)
+		var channels []object
+		for i := room * 5; i < deviceCount && i < room*5+5; i++ {
+			channels = append(channels, object{"deviceId": syntheticDeviceID(i), "channelIndex": 1})
+		}
+		groups[metaID] = object{
+			"id":               metaID,
+			"type":             GROUP_TYPE_META,
+			"label":            fmt.Sprintf("Room %d", room),
+			"lastStatusUpdate": 1700000000000,
+			"groupIcon":        "LIVINGROOM",
+			"groups":           []string{heatingID},
+			"channels":         channels,
+		}
+		groups[heatingID] = object{
+			"id":                    heatingID,
+			"type":                  GROUP_TYPE_HEATING,
+			"label":                 fmt.Sprintf("Heating %d", room),
+			"lastStatusUpdate":      1700000000000,
+			"actualTemperature":     21.5,
+			"humidity":              45,
+			"setPointTemperature":   21,
+			"windowOpenTemperature": 5,
+			"channels":              channels,
+		}
+	}
+	return object{
+		"home": object{
+			"id":                 "home",
+			"currentAPVersion":   "1.4.8",
+			"availableAPVersion": "1.4.8",
+			"connected":          true,
+			"dutyCycle":          8.5,
+			"carrierSense":       2.5,
+			"timeZoneId":         "Europe/Berlin",
+			"updateState":        HOME_UPDATE_STATE_UP_TO_DATE,
+			"weather": object{
+				"temperature":      12.3,
+				"weatherCondition": WEATHER_CONDITION_CLOUDY,
+				"weatherDayTime":   WEATHER_DAY_TIME_DAY,
+				"humidity":         80,
+				"windSpeed":        10.5,
+			},
+			"location": object{
+				"city":      "Berlin",
+				"latitude":  "52.520008",
+				"longitude": "13.404954",
+			},
+			"functionalHomes": object{
+				"INDOOR_CLIMATE": object{
+					"solution":         "INDOOR_CLIMATE",
+					"active":           true,
+					"absenceType":      ABSENCE_TYPE_NOT_ABSENT,
+					"functionalGroups": []string{"heating-0"},
+				},
+			},
+		},
+		"devices": devices,
+		"groups":  groups,
+		"clients": object{
+			"client": object{
+				"id":                  "client",
+				"label":               "Benchmark",
+				"type":                "APP",
+				"createdAtTimestamp":  1700000000000,
+				"lastSeenAtTimestamp": 1700000000000,
+			},
+		},
+	}
+}
+
+func syntheticDevice(i int, timestamp int64) object {
+	id := syntheticDeviceID(i)
+	channels := object{
+		"0": object{
+			"functionalChannelType": CHANNEL_TYPE_DEVICE_BASE,
+			"index":                 0,
+			"label":                 "",
+			"deviceId":              id,
+			"groups":                []string{},
+			"lowBat":                false,
+			"rssiDeviceValue":       -60 - i%30,
+			"unreach":               false,
+			"deviceUndervoltage":    false,
+			"deviceOverheated":      false,
+			"dutyCycle":             false,
+			"configPending":         false,
+		},
+	}
+	deviceType := DEVICE_TYPE_TEMPERATURE_HUMIDITY_SENSOR_OUTDOOR
+	channel := object{
+		"index":    1,
+		"label":    "",
+		"deviceId": id,
+		"groups":   []string{fmt.Sprintf("meta-%d", i/5)},
+	}
+	switch i % 3 {
+	case 0:
+		deviceType = DEVICE_TYPE_PLUGABLE_SWITCH_MEASURING
+		channel["functionalChannelType"] = CHANNEL_TYPE_SWITCH_MEASURING
+		channel["on"] = i%2 == 0
+		channel["currentPowerConsumption"] = float64(i) * 1.5
+		channel["energyCounter"] = float64(i) * 100.25
+	case 1:
+		channel["functionalChannelType"] = CHANNEL_TYPE_CLIMATE_SENSOR
+		channel["actualTemperature"] = 20 + float64(i%10)/10
+		channel["humidity"] = 40 + i%20
+		channel["vaporAmount"] = 8.5
+	case 2:
+		deviceType = DEVICE_TYPE_SMOKE_DETECTOR
+		channel["functionalChannelType"] = CHANNEL_TYPE_SMOKE_DETECTOR
+		channel["chamberDegraded"] = false
+		channel["smokeDetectorAlarmType"] = "IDLE_OFF"
+	}
+	channels["1"] = channel
+	return object{
+		"id":                              id,
+		"type":                            deviceType,
+		"label":                           fmt.Sprintf("Device %d", i),
+		"modelType":                       "HmIP-BENCH",
+		"serializedGlobalTradeItemNumber": id,
+		"lastStatusUpdate":                1700000000000 + timestamp,
+		"firmwareVersion":                 "1.2.3",
+		"availableFirmwareVersion":        "0.0.0",
+		"updateState":                     DEVICE_UPDATE_STATE_UP_TO_DATE,
+		"liveUpdateState":                 LIVE_UPDATE_STATE_LIVE_UPDATE_NOT_SUPPORTED,
+		"connectionType":                  CONNECTION_TYPE_RF,
+		"permanentlyReachable":            i%3 == 0,
+		"functionalChannels":              channels,
+	}
+}
+
+func syntheticPushMessage(deviceCount, eventCount, message int) object {
+	events := make(object, eventCount)
+	for i := 0; i < eventCount; i++ {
+		device := (message*eventCount + i) % deviceCount
+		events[fmt.Sprint(i)] = object{
+			"pushEventType": EVENT_TYPE_DEVICE_CHANGED,
+			"device":        syntheticDevice(device, int64(message)),
+		}
+	}
+	return object{
+		"events": events,
+		"origin": object{
+			"originType": ORIGIN_TYPE_DEVICE,
+			"id":         syntheticDeviceID(message % deviceCount),
+		},
+		"accessPointId": "3014F711A0000000000000001",
+	}
+}
@@ -1,6 +1,82 @@
 package hmip
 
-import "time"
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type raw struct {
+	Raw json.RawMessage `json:"-"`
+	// attributes caches the decoded raw JSON, it is shared by all copies of the entity
+	attributes *rawAttributes
+}
+
+type rawAttributes struct {
+	once  sync.Once
+	value any
+}
+
+// setRaw sets the raw JSON and resets the decoded attributes.
+func (r *raw) setRaw(value json.RawMessage) {
+	r.Raw = value
+	r.attributes = &rawAttributes{}
+}
+
+func (r raw) GetRaw() json.RawMessage {
+	return r.Raw
+}
+
+// MarshalJSON returns the original JSON, so all entities are marshalled
+// back into the wire format of the HomematicIP Cloud.
+func (r raw) MarshalJSON() ([]byte, error) {
+	if r.Raw == nil {
+		return []byte("null"), nil
+	}
+	return r.Raw, nil
+}
+
+func (r raw) GetAttribute(path string) any {
+	value := r.decodedAttributes()
+	for _, key := range strings.Split(path, ".") {
+		switch current := value.(type) {
+		case map[string]any:
+			value = current[key]
+		case []any:
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || index >= len(current) {
+				return nil
+			}
+			value = current[index]
+		default:
+			return nil
+		}
+	}
+	return value
+}
+
+// decodedAttributes decodes the raw JSON on first use only. Entities with
+// a Raw set directly instead of by setRaw are decoded on every use.
+func (r raw) decodedAttributes() any {
+	decode := func() any {
+		var value any
+		if json.Unmarshal(r.Raw, &value) != nil {
+			return nil
+		}
+		return value
+	}
+	if r.attributes == nil {
+		return decode()
+	}
+	r.attributes.once.Do(func() {
+		r.attributes.value = decode()
+	})
+	return r.attributes.value
+}
+
+// ======================================================
 
 type named struct {
 	Name string `json:"label"`
@@ -46,6 +122,30 @@ func (s switchable) IsSwitchedOn() bool {
 
 // ======================================================
 
+type openable struct {
+	WindowState string `json:"windowState"`
+}
+
+func (o openable) GetWindowState() string {
+	return o.WindowState
+}
+
+func (o openable) IsOpen() bool {
+	return o.WindowState == WINDOW_STATE_OPEN || o.WindowState == WINDOW_STATE_TILTED
+}
+
+// ======================================================
+
+type dimmable struct {
+	DimLevel float64 `json:"dimLevel"`
+}
+
+func (d dimmable) GetDimLevel() float64 {
+	return d.DimLevel
+}
+
+// ======================================================
+
 type powerConsumptionMeasuring struct {
 	CurrentPowerConsumption float64 `json:"currentPowerConsumption"`
 }
@@ -73,3 +173,38 @@ func (cm climateMeasuring) GetHumidity() int {
 func (cm climateMeasuring) GetVapourAmount() float64 {
 	return cm.VapourAmount
 }
+
+// ======================================================
+
+type shutterLevelled struct {
+	ShutterLevel         float64 `json:"shutterLevel"`
+	PreviousShutterLevel float64 `json:"previousShutterLevel"`
+	Processing           bool    `json:"processing"`
+}
+
+func (sl shutterLevelled) GetShutterLevel() float64 {
+	return sl.ShutterLevel
+}
+
+func (sl shutterLevelled) GetPreviousShutterLevel() float64 {
+	return sl.PreviousShutterLevel
+}
+
+func (sl shutterLevelled) IsProcessing() bool {
+	return sl.Processing
+}
+
+// ======================================================
+
+type illuminationMeasuring struct {
+	Illumination        float64 `json:"illumination"`
+	CurrentIllumination float64 `json:"currentIllumination"`
+}
+
+func (im illuminationMeasuring) GetIllumination() float64 {
+	return im.Illumination
+}
+
+func (im illuminationMeasuring) GetCurrentIllumination() float64 {
+	return im.CurrentIllumination
+}
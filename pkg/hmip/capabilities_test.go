@@ -0,0 +1,60 @@
+package hmip
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestGetAttribute(t *testing.T) {
+	state, err := ParseState([]byte(`{"devices":{"device-1":{"id":"device-1","type":"PLUGABLE_SWITCH","label":"Lamp",
+		"functionalChannels":{"1":{"functionalChannelType":"SWITCH_CHANNEL","index":1,"on":true,"groups":["group-1","group-2"]}}}}}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	device := state.GetDeviceByID("device-1")
+	tests := []struct {
+		path     string
+		expected any
+	}{
+		{"label", "Lamp"},
+		{"functionalChannels.1.on", true},
+		{"functionalChannels.1.index", float64(1)},
+		{"functionalChannels.1.groups.1", "group-2"},
+		{"functionalChannels.1.groups.2", nil},
+		{"functionalChannels.2.on", nil},
+		{"label.missing", nil},
+		{"missing", nil},
+	}
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			if value := device.GetAttribute(test.path); !reflect.DeepEqual(value, test.expected) {
+				t.Errorf("expected %v, got %v", test.expected, value)
+			}
+		})
+	}
+}
+
+func TestGetAttributeDecodesOnce(t *testing.T) {
+	entity := &raw{}
+	entity.setRaw([]byte(`{"settings":{"mode":"AUTO"}}`))
+	copied := *entity
+
+	var wait sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wait.Add(1)
+		go func() {
+			defer wait.Done()
+			if mode := copied.GetAttribute("settings.mode"); mode != "AUTO" {
+				t.Errorf("expected AUTO, got %v", mode)
+			}
+		}()
+	}
+	wait.Wait()
+
+	first := entity.GetAttribute("settings").(map[string]any)
+	second := copied.GetAttribute("settings").(map[string]any)
+	if reflect.ValueOf(first).Pointer() != reflect.ValueOf(second).Pointer() {
+		t.Error("expected the decoded attributes to be shared by copies")
+	}
+}
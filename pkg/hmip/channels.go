@@ -1,25 +1,54 @@
 package hmip
 
-import "encoding/json"
+import "bytes"
 
 type functionalChannel struct {
-	Type string `json:"functionalChannelType"`
+	raw
+	Type     string   `json:"functionalChannelType"`
+	Index    int      `json:"index"`
+	Label    string   `json:"label"`
+	DeviceID string   `json:"deviceId"`
+	GroupIDs []string `json:"groups"`
+	device   Device
 }
 
 func (fc functionalChannel) GetType() string {
 	return fc.Type
 }
 
+func (fc functionalChannel) GetIndex() int {
+	return fc.Index
+}
+
+func (fc functionalChannel) GetLabel() string {
+	return fc.Label
+}
+
+func (fc functionalChannel) GetDeviceID() string {
+	return fc.DeviceID
+}
+
+func (fc functionalChannel) GetGroupIDs() []string {
+	return fc.GroupIDs
+}
+
+func (fc functionalChannel) GetDevice() Device {
+	return fc.device
+}
+
+func (fc *functionalChannel) channelBase() *functionalChannel {
+	return fc
+}
+
 // ======================================================
 
 type baseDeviceChannel struct {
-	functionalChannel
-	LowBattery   bool     `json:"lowBat"`
-	RSSIValue    int      `json:"rssiDeviceValue"`
-	Unreached    bool     `json:"unreach"`
-	UnderVoltage bool     `json:"deviceUndervoltage"`
-	Overheated   bool     `json:"deviceOverheated"`
-	Groups       []string `json:"groups"`
+	*functionalChannel
+	LowBattery   bool `json:"lowBat"`
+	RSSIValue    int  `json:"rssiDeviceValue"`
+	Unreached    bool `json:"unreach"`
+	UnderVoltage bool `json:"deviceUndervoltage"`
+	Overheated   bool `json:"deviceOverheated"`
 }
 
 func (bdc baseDeviceChannel) HasLowBattery() bool {
@@ -43,20 +72,22 @@ func (bdc baseDeviceChannel) IsOverheated() bool {
 }
 
 func (bdc baseDeviceChannel) GetGroups() []string {
-	return bdc.Groups
+	return bdc.GroupIDs
 }
 
 // ======================================================
 
 type switchChannel struct {
-	functionalChannel
+	*functionalChannel
 	switchable
 }
 
+func (sc switchChannel) isSwitchChannel() {}
+
 // ======================================================
 
 type switchMeasuringChannel struct {
-	functionalChannel
+	*functionalChannel
 	switchable
 	powerConsumptionMeasuring
 }
@@ -64,14 +95,16 @@ type switchMeasuringChannel struct {
 // ======================================================
 
 type climateSensorChannel struct {
-	functionalChannel
+	*functionalChannel
 	climateMeasuring
 }
 
+func (csc climateSensorChannel) isClimateSensorChannel() {}
+
 // ======================================================
 
 type smokeDetectorChannel struct {
-	functionalChannel
+	*functionalChannel
 	ChamberDegraded bool `json:"chamberDegraded"`
 }
 
@@ -81,69 +114,179 @@ func (sdc smokeDetectorChannel) IsChamberDegraded() bool {
 
 // ======================================================
 
+type heatingThermostatChannel struct {
+	*functionalChannel
+	ValvePosition          float64 `json:"valvePosition"`
+	ValveState             string  `json:"valveState"`
+	SetPointTemperature    float64 `json:"setPointTemperature"`
+	TemperatureOffset      float64 `json:"temperatureOffset"`
+	ValveActualTemperature float64 `json:"valveActualTemperature"`
+}
+
+func (htc heatingThermostatChannel) GetValvePosition() float64 {
+	return htc.ValvePosition
+}
+
+func (htc heatingThermostatChannel) GetValveState() string {
+	return htc.ValveState
+}
+
+func (htc heatingThermostatChannel) GetSetPointTemperature() float64 {
+	return htc.SetPointTemperature
+}
+
+func (htc heatingThermostatChannel) GetTemperatureOffset() float64 {
+	return htc.TemperatureOffset
+}
+
+func (htc heatingThermostatChannel) GetValveActualTemperature() float64 {
+	return htc.ValveActualTemperature
+}
+
+// ======================================================
+
+type wallMountedThermostatChannel struct {
+	*functionalChannel
+	climateMeasuring
+	SetPointTemperature float64 `json:"setPointTemperature"`
+	TemperatureOffset   float64 `json:"temperatureOffset"`
+}
+
+func (wmtc wallMountedThermostatChannel) GetSetPointTemperature() float64 {
+	return wmtc.SetPointTemperature
+}
+
+func (wmtc wallMountedThermostatChannel) GetTemperatureOffset() float64 {
+	return wmtc.TemperatureOffset
+}
+
+// ======================================================
+
+type wallMountedThermostatProChannel struct {
+	wallMountedThermostatChannel
+	Display string `json:"display"`
+}
+
+func (wmtpc wallMountedThermostatProChannel) GetDisplay() string {
+	return wmtpc.Display
+}
+
+// ======================================================
+
+type shutterChannel struct {
+	*functionalChannel
+	shutterLevelled
+	TopToBottomReferenceTime float64 `json:"topToBottomReferenceTime"`
+	BottomToTopReferenceTime float64 `json:"bottomToTopReferenceTime"`
+}
+
+func (sc shutterChannel) GetTopToBottomReferenceTime() float64 {
+	return sc.TopToBottomReferenceTime
+}
+
+func (sc shutterChannel) GetBottomToTopReferenceTime() float64 {
+	return sc.BottomToTopReferenceTime
+}
+
+// ======================================================
+
+type blindChannel struct {
+	shutterChannel
+	SlatsLevel         float64 `json:"slatsLevel"`
+	PreviousSlatsLevel float64 `json:"previousSlatsLevel"`
+}
+
+func (bc blindChannel) GetSlatsLevel() float64 {
+	return bc.SlatsLevel
+}
+
+func (bc blindChannel) GetPreviousSlatsLevel() float64 {
+	return bc.PreviousSlatsLevel
+}
+
+// ======================================================
+
+type dimmerChannel struct {
+	*functionalChannel
+	switchable
+	dimmable
+}
+
+func (dc dimmerChannel) isDimmerChannel() {}
+
+// ======================================================
+
+type notificationLightChannel struct {
+	*functionalChannel
+	switchable
+	dimmable
+	SimpleRGBColorState string `json:"simpleRGBColorState"`
+}
+
+func (nlc notificationLightChannel) GetSimpleRGBColorState() string {
+	return nlc.SimpleRGBColorState
+}
+
+func (nlc notificationLightChannel) isNotificationLightChannel() {}
+
+// ======================================================
+
+type contactChannel struct {
+	*functionalChannel
+	openable
+	EventDelay float64 `json:"eventDelay"`
+	Sabotage   bool    `json:"sabotage"`
+}
+
+func (cc contactChannel) GetEventDelay() float64 {
+	return cc.EventDelay
+}
+
+func (cc contactChannel) HasSabotage() bool {
+	return cc.Sabotage
+}
+
+// ======================================================
+
+type motionDetectionChannel struct {
+	*functionalChannel
+	illuminationMeasuring
+	MotionDetected              bool   `json:"motionDetected"`
+	MotionDetectionSendInterval string `json:"motionDetectionSendInterval"`
+}
+
+func (mdc motionDetectionChannel) IsMotionDetected() bool {
+	return mdc.MotionDetected
+}
+
+func (mdc motionDetectionChannel) GetMotionDetectionSendInterval() string {
+	return mdc.MotionDetectionSendInterval
+}
+
+// ======================================================
+
+type presenceDetectionChannel struct {
+	*functionalChannel
+	illuminationMeasuring
+	PresenceDetected            bool   `json:"presenceDetected"`
+	MotionDetectionSendInterval string `json:"motionDetectionSendInterval"`
+}
+
+func (pdc presenceDetectionChannel) IsPresenceDetected() bool {
+	return pdc.PresenceDetected
+}
+
+func (pdc presenceDetectionChannel) GetMotionDetectionSendInterval() string {
+	return pdc.MotionDetectionSendInterval
+}
+
+// ======================================================
+
 func (fc *FunctionalChannels) UnmarshalJSON(value []byte) error {
-	var channelValues map[string]json.RawMessage
-	err := json.Unmarshal(value, &channelValues)
+	channels, err := newDecoder(DecodeOptions{}).decodeChannels("functionalChannels", bytes.Clone(value), nil)
 	if err != nil {
 		return err
 	}
-	channels := make(FunctionalChannels, 0, len(channelValues))
-	for _, channelValue := range channelValues {
-		var channel functionalChannel
-		err = json.Unmarshal(channelValue, &channel)
-		if err != nil {
-			return err
-		}
-		switch channel.Type {
-		case CHANNEL_TYPE_DEVICE_BASE:
-			specialChannel := baseDeviceChannel{
-				functionalChannel: channel,
-			}
-			err = json.Unmarshal(channelValue, &specialChannel)
-			if err != nil {
-				return err
-			}
-			channels = append(channels, &specialChannel)
-		case CHANNEL_TYPE_SWITCH:
-			specialChannel := switchChannel{
-				functionalChannel: channel,
-			}
-			err = json.Unmarshal(channelValue, &specialChannel)
-			if err != nil {
-				return err
-			}
-			channels = append(channels, &specialChannel)
-		case CHANNEL_TYPE_SWITCH_MEASURING:
-			specialChannel := switchMeasuringChannel{
-				functionalChannel: channel,
-			}
-			err = json.Unmarshal(channelValue, &specialChannel)
-			if err != nil {
-				return err
-			}
-			channels = append(channels, &specialChannel)
-		case CHANNEL_TYPE_CLIMATE_SENSOR:
-			specialChannel := climateSensorChannel{
-				functionalChannel: channel,
-			}
-			err = json.Unmarshal(channelValue, &specialChannel)
-			if err != nil {
-				return err
-			}
-			channels = append(channels, &specialChannel)
-		case CHANNEL_TYPE_SMOKE_DETECTOR:
-			specialChannel := smokeDetectorChannel{
-				functionalChannel: channel,
-			}
-			err = json.Unmarshal(channelValue, &specialChannel)
-			if err != nil {
-				return err
-			}
-			channels = append(channels, &specialChannel)
-		default:
-			channels = append(channels, &channel)
-		}
-	}
 	*fc = channels
 	return nil
 }
@@ -0,0 +1,184 @@
+package hmip
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestShutterAndBlindChannels(t *testing.T) {
+	state := parseFixtureState(t)
+
+	shutters := state.GetFunctionalChannelsByType(DEVICE_TYPE_BRAND_SHUTTER, CHANNEL_TYPE_SHUTTER)
+	if len(shutters) != 1 {
+		t.Fatalf("expected one shutter channel, got %d", len(shutters))
+	}
+	shutter, ok := shutters[0].(ShutterChannel)
+	if !ok {
+		t.Fatal("expected ShutterChannel")
+	}
+	if shutter.GetShutterLevel() != 0.75 || shutter.IsProcessing() || shutter.GetTopToBottomReferenceTime() != 30.08 || shutter.GetBottomToTopReferenceTime() != 31.02 {
+		t.Errorf("unexpected shutter channel %+v", shutter)
+	}
+
+	blinds := state.GetFunctionalChannelsByType(DEVICE_TYPE_BRAND_BLIND, CHANNEL_TYPE_BLIND)
+	if len(blinds) != 1 {
+		t.Fatalf("expected one blind channel, got %d", len(blinds))
+	}
+	blind, ok := blinds[0].(BlindChannel)
+	if !ok {
+		t.Fatal("expected BlindChannel")
+	}
+	if blind.GetShutterLevel() != 1 || blind.GetSlatsLevel() != 0.5 || blind.GetTopToBottomReferenceTime() != 41.5 {
+		t.Errorf("unexpected blind channel %+v", blind)
+	}
+	if state.Devices().Where(OfType(DEVICE_TYPE_BRAND_SHUTTER, DEVICE_TYPE_FULL_FLUSH_SHUTTER, DEVICE_TYPE_BRAND_BLIND, DEVICE_TYPE_FULL_FLUSH_BLIND, DEVICE_TYPE_DIN_RAIL_BLIND_4)).Count() != 2 {
+		t.Error("expected the shutter and blind devices")
+	}
+}
+
+func TestDimmerAndNotificationLightChannels(t *testing.T) {
+	state := parseFixtureState(t)
+
+	dimmers := ChannelsOf[DimmerChannel](state)
+	if len(dimmers) != 1 {
+		t.Fatalf("expected one dimmer channel, got %d", len(dimmers))
+	}
+	dimmer := dimmers[0].Channel
+	if dimmers[0].Device.GetID() != "3014F711A0000E1BE9A3C0D7" || dimmer.GetDimLevel() != 0.35 || dimmer.IsSwitchedOn() {
+		t.Errorf("unexpected dimmer channel %+v", dimmer)
+	}
+
+	notificationLight := state.GetDeviceByID("3014F711A0000E9BE9A3C0DE")
+	lights := ChannelsOfDevice[NotificationLightChannel](notificationLight)
+	if len(lights) != 2 {
+		t.Fatalf("expected two notification light channels, got %d", len(lights))
+	}
+	if lights[0].GetLabel() != "Oben" || !lights[0].IsSwitchedOn() || lights[0].GetDimLevel() != 1 || lights[0].GetSimpleRGBColorState() != RGB_COLOR_STATE_GREEN {
+		t.Errorf("unexpected upper notification light channel %+v", lights[0])
+	}
+	if lights[1].GetLabel() != "Unten" || lights[1].IsSwitchedOn() || lights[1].GetDimLevel() != 0 || lights[1].GetSimpleRGBColorState() != RGB_COLOR_STATE_BLACK {
+		t.Errorf("unexpected lower notification light channel %+v", lights[1])
+	}
+
+	// Dimmers and notification lights are switchable, but neither switch channels nor each other
+	switches := ChannelsOf[SwitchChannel](state)
+	if len(switches) != 1 || switches[0].Device.GetID() != "3014F711A0000E9BE9A3C0DE" || switches[0].Channel.GetType() != CHANNEL_TYPE_SWITCH {
+		t.Errorf("expected only the switch channel of the notification light device, got %v", switches)
+	}
+	if len(ChannelsOfDevice[DimmerChannel](notificationLight)) != 0 {
+		t.Error("expected notification light channels not to be dimmer channels")
+	}
+	if len(ChannelsOf[Dimmable](state)) != 3 {
+		t.Error("expected the dimmer and the notification lights to be dimmable")
+	}
+}
+
+func TestHeatingThermostatChannel(t *testing.T) {
+	state := parseFixtureState(t)
+
+	device := state.GetDeviceByID("3014F711A0000E9A49AB14C3")
+	if device.GetType() != DEVICE_TYPE_HEATING_THERMOSTAT {
+		t.Fatalf("expected heating thermostat, got %s", device.GetType())
+	}
+	thermostat, ok := FirstChannel[HeatingThermostatChannel](device)
+	if !ok {
+		t.Fatal("expected HeatingThermostatChannel")
+	}
+	if thermostat.GetType() != CHANNEL_TYPE_HEATING_THERMOSTAT || thermostat.GetIndex() != 1 {
+		t.Errorf("unexpected channel %s with index %d", thermostat.GetType(), thermostat.GetIndex())
+	}
+	if thermostat.GetValvePosition() != 0.37 {
+		t.Errorf("expected valve position 0.37, got %f", thermostat.GetValvePosition())
+	}
+	if thermostat.GetValveState() != VALVE_STATE_ADAPTION_DONE {
+		t.Errorf("expected valve state %s, got %s", VALVE_STATE_ADAPTION_DONE, thermostat.GetValveState())
+	}
+	if thermostat.GetSetPointTemperature() != 21 {
+		t.Errorf("expected set point temperature 21.0, got %f", thermostat.GetSetPointTemperature())
+	}
+	if thermostat.GetTemperatureOffset() != 0 {
+		t.Errorf("expected temperature offset 0.0, got %f", thermostat.GetTemperatureOffset())
+	}
+	if thermostat.GetValveActualTemperature() != 20.4 {
+		t.Errorf("expected valve actual temperature 20.4, got %f", thermostat.GetValveActualTemperature())
+	}
+	if len(ChannelsOf[HeatingThermostatChannel](state)) != 1 {
+		t.Error("expected only the channel of the heating thermostat")
+	}
+}
+
+func TestHeatingThermostatValveStates(t *testing.T) {
+	for _, valveState := range []string{VALVE_STATE_ADAPTION_IN_PROGRESS, VALVE_STATE_ERROR_POSITION, VALVE_STATE_TOO_TIGHT, VALVE_STATE_STATE_NOT_AVAILABLE} {
+		channel, err := decodeChannelByType(CHANNEL_TYPE_HEATING_THERMOSTAT, `"valveState":"`+valveState+`","valvePosition":0.0,"temperatureOffset":1.5`)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		thermostat, ok := channel.(HeatingThermostatChannel)
+		if !ok {
+			t.Fatalf("expected HeatingThermostatChannel for %s", valveState)
+		}
+		if thermostat.GetValveState() != valveState || thermostat.GetValvePosition() != 0 || thermostat.GetTemperatureOffset() != 1.5 {
+			t.Errorf("unexpected channel for %s: %+v", valveState, thermostat)
+		}
+	}
+}
+
+// decodeChannelByType decodes a device with a single channel of the type and the attributes.
+func decodeChannelByType(channelType, attributes string) (FunctionalChannel, error) {
+	state, err := ParseState([]byte(`{"devices":{"device":{"id":"device","type":"DEVICE","functionalChannels":{
+		"1":{"functionalChannelType":"` + channelType + `","index":1,` + attributes + `}}}}}`))
+	if err != nil {
+		return nil, err
+	}
+	return state.GetDeviceByID("device").GetChannel(1), nil
+}
+
+func TestWallMountedThermostatChannels(t *testing.T) {
+	state, err := ParseState([]byte(`{"devices":{
+		"wth":{"id":"wth","type":"WALL_MOUNTED_THERMOSTAT_PRO","label":"Wall thermostat","functionalChannels":{
+			"1":{"functionalChannelType":"WALL_MOUNTED_THERMOSTAT_PRO_CHANNEL","index":1,"actualTemperature":21.3,"humidity":52,
+				"vaporAmount":9.8,"setPointTemperature":22.0,"temperatureOffset":-0.5,"display":"SETPOINT"}}},
+		"sth":{"id":"sth","type":"TEMPERATURE_HUMIDITY_SENSOR","label":"Sensor thermostat","functionalChannels":{
+			"1":{"functionalChannelType":"WALL_MOUNTED_THERMOSTAT_WITHOUT_DISPLAY_CHANNEL","index":1,"actualTemperature":19.6,"humidity":61,
+				"vaporAmount":10.3,"setPointTemperature":18.0,"temperatureOffset":0.5}}},
+		"sensor":{"id":"sensor","type":"TEMPERATURE_HUMIDITY_SENSOR_OUTDOOR","label":"Outdoor sensor","functionalChannels":{
+			"1":{"functionalChannelType":"CLIMATE_SENSOR_CHANNEL","index":1,"actualTemperature":4.5,"humidity":88,"vaporAmount":5.9}}}}}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	pro, ok := FirstChannel[WallMountedThermostatProChannel](state.GetDeviceByID("wth"))
+	if !ok {
+		t.Fatal("expected WallMountedThermostatProChannel")
+	}
+	if pro.GetActualTemperature() != 21.3 || pro.GetHumidity() != 52 || pro.GetVapourAmount() != 9.8 ||
+		pro.GetSetPointTemperature() != 22 || pro.GetTemperatureOffset() != -0.5 || pro.GetDisplay() != CLIMATE_CONTROL_DISPLAY_SETPOINT {
+		t.Errorf("unexpected wall-mounted thermostat pro channel %+v", pro)
+	}
+
+	withoutDisplay, ok := FirstChannel[WallMountedThermostatChannel](state.GetDeviceByID("sth"))
+	if !ok {
+		t.Fatal("expected WallMountedThermostatChannel")
+	}
+	if _, ok := withoutDisplay.(WallMountedThermostatProChannel); ok {
+		t.Error("expected the channel without display not to be a WallMountedThermostatProChannel")
+	}
+	if withoutDisplay.GetType() != CHANNEL_TYPE_WALL_MOUNTED_THERMOSTAT_WITHOUT_DISPLAY || withoutDisplay.GetActualTemperature() != 19.6 || withoutDisplay.GetHumidity() != 61 ||
+		withoutDisplay.GetVapourAmount() != 10.3 || withoutDisplay.GetSetPointTemperature() != 18 || withoutDisplay.GetTemperatureOffset() != 0.5 {
+		t.Errorf("unexpected wall-mounted thermostat channel %+v", withoutDisplay)
+	}
+
+	// Both thermostats appear in climate reports alongside the climate sensor, but are no climate sensors
+	measuring := ChannelsOf[ClimateMeasuring](state)
+	var deviceIDs []string
+	for _, channel := range measuring {
+		deviceIDs = append(deviceIDs, channel.Device.GetID())
+	}
+	slices.Sort(deviceIDs)
+	if !slices.Equal(deviceIDs, []string{"sensor", "sth", "wth"}) {
+		t.Errorf("expected all three channels to be climate measuring, got %v", deviceIDs)
+	}
+	if sensors := ChannelsOf[ClimateSensorChannel](state); len(sensors) != 1 || sensors[0].Device.GetID() != "sensor" {
+		t.Errorf("expected only the climate sensor channel, got %v", sensors)
+	}
+}
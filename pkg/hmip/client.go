@@ -1,11 +1,12 @@
 package hmip
 
 import (
-	"encoding/json"
+	"bytes"
 	"time"
 )
 
 type client struct {
+	raw
 	named
 	typed
 	ID       string             `json:"id"`
@@ -28,20 +29,10 @@ func (c client) GetCreated() time.Time {
 // ======================================================
 
 func (c *Clients) UnmarshalJSON(value []byte) error {
-	var clientValues map[string]json.RawMessage
-	err := json.Unmarshal(value, &clientValues)
+	clients, err := newDecoder(DecodeOptions{}).decodeClients("clients", bytes.Clone(value))
 	if err != nil {
 		return err
 	}
-	clients := make(Clients, 0, len(clientValues))
-	for _, clientValue := range clientValues {
-		var client client
-		err = json.Unmarshal(clientValue, &client)
-		if err != nil {
-			return err
-		}
-		clients = append(clients, &client)
-	}
 	*c = clients
 	return nil
 }
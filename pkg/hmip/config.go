@@ -2,6 +2,7 @@ package hmip
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -30,10 +31,11 @@ const (
 	EnvVarNameDeviceId         = "HMIP_DEVICE_ID"
 	EnvVarNameClientAuthToken  = "HMIP_CLIENT_AUTH_TOKEN"
 	EnvVarNameAuthToken        = "HMIP_AUTH_TOKEN"
+	EnvVarNameLenientDecoding  = "HMIP_LENIENT_DECODING"
 )
 
 type Config struct {
-	AccessPointSGTIN  string
+	AccessPointSGTIN  SGTIN
 	ClientName        string
 	RestEndpoint      string
 	WebSocketEndpoint string
@@ -43,26 +45,40 @@ type Config struct {
 	ClientID          string
 	ClientAuthToken   string
 	AuthToken         string
+	// AcknowledgeTimeout limits the time to wait for the blue button to be pressed
+	// when registering a new client, zero waits for a fixed number of attempts
+	AcknowledgeTimeout time.Duration
+	// LenientDecoding skips malformed entries in states and events instead of failing (see DecodeOptions)
+	LenientDecoding bool
 }
 
 func GetConfig() (*Config, error) {
 	config := Config{
 		LookupEndpoint:   LookupEndpoint,
-		AccessPointSGTIN: os.Getenv(EnvVarNameAccessPointSGTIN),
+		AccessPointSGTIN: SGTIN(os.Getenv(EnvVarNameAccessPointSGTIN)),
 		PIN:              os.Getenv(EnvVarNamePIN),
 		ClientID:         os.Getenv(EnvVarNameClientId),
 		ClientName:       os.Getenv(EnvVarNameClientName),
 		ClientAuthToken:  os.Getenv(EnvVarNameClientAuthToken),
 		DeviceID:         os.Getenv(EnvVarNameDeviceId),
 		AuthToken:        os.Getenv(EnvVarNameAuthToken),
+		LenientDecoding:  os.Getenv(EnvVarNameLenientDecoding) == "true",
 	}
 	return &config, nil
 }
 
 func (c *Config) RegisterClient(handshakeCallback func()) error {
+	return c.RegisterClientWithContext(context.Background(), handshakeCallback)
+}
+
+// RegisterClientWithContext registers a new client like RegisterClient. All requests end when
+// the context is done, waiting for the acknowledge on the access point additionally ends
+// after the AcknowledgeTimeout. Errors are returned as *RegistrationError containing the
+// failed registration step.
+func (c *Config) RegisterClientWithContext(ctx context.Context, handshakeCallback func()) error {
 	err := c.lookupEndpoints()
 	if err != nil {
-		return err
+		return &RegistrationError{Step: REGISTRATION_STEP_LOOKUP, Err: err}
 	}
 	c.createClientAuthToken()
 	c.createDeviceID()
@@ -73,26 +89,50 @@ func (c *Config) RegisterClient(handshakeCallback func()) error {
 		},
 		Timeout: 30 * time.Second,
 	}
-	err = c.connectionRequest(httpClient)
+	err = c.connectionRequest(ctx, httpClient)
 	if err != nil {
-		return err
+		return &RegistrationError{Step: REGISTRATION_STEP_CONNECTION_REQUEST, Err: err}
 	}
 	handshakeCallback()
-	err = c.requestAcknowledge(httpClient)
+	acknowledgeCtx := ctx
+	if c.AcknowledgeTimeout > 0 {
+		var cancel context.CancelFunc
+		acknowledgeCtx, cancel = context.WithTimeout(ctx, c.AcknowledgeTimeout)
+		defer cancel()
+	}
+	err = c.requestAcknowledge(acknowledgeCtx, httpClient)
 	if err != nil {
-		return err
+		return &RegistrationError{Step: REGISTRATION_STEP_ACKNOWLEDGE, Err: err}
 	}
-	err = c.requestAuthToken(httpClient)
+	err = c.requestAuthToken(ctx, httpClient)
 	if err != nil {
-		return err
+		return &RegistrationError{Step: REGISTRATION_STEP_AUTH_TOKEN, Err: err}
+	}
+	err = c.confirmAuthToken(ctx, httpClient)
+	if err != nil {
+		return &RegistrationError{Step: REGISTRATION_STEP_CONFIRM_AUTH_TOKEN, Err: err}
 	}
-	return c.confirmAuthToken(httpClient)
+	return nil
+}
+
+// RegistrationError is returned when registering a new client fails.
+type RegistrationError struct {
+	Step string
+	Err  error
+}
+
+func (e *RegistrationError) Error() string {
+	return fmt.Sprintf("Registration failed in step %s: %v", e.Step, e.Err)
+}
+
+func (e *RegistrationError) Unwrap() error {
+	return e.Err
 }
 
 // ======================================================
 
 func (c *Config) createClientAuthToken() {
-	tokenDigest := digest.SHA512.FromBytes([]byte(c.getTrimmedAccessPointSGTIN() + "jiLpVitHvWnIGD1yo7MA"))
+	tokenDigest := digest.SHA512.FromBytes([]byte(string(c.AccessPointSGTIN.Canonical()) + "jiLpVitHvWnIGD1yo7MA"))
 	c.ClientAuthToken = strings.ToUpper(tokenDigest.Hex())
 }
 
@@ -100,13 +140,13 @@ func (c *Config) createDeviceID() {
 	c.DeviceID = uuid.New().String()
 }
 
-func (c *Config) connectionRequest(httpClient *http.Client) error {
+func (c *Config) connectionRequest(ctx context.Context, httpClient *http.Client) error {
 	requestBody, _ := json.Marshal(registerClientRequest{
 		DeviceID:         c.DeviceID,
 		DeviceName:       c.ClientName,
-		AccessPointSGTIN: c.getTrimmedAccessPointSGTIN(),
+		AccessPointSGTIN: c.AccessPointSGTIN.Canonical(),
 	})
-	request, requestErr := http.NewRequest("POST", c.RestEndpoint+"/hmip/auth/connectionRequest", bytes.NewReader(requestBody))
+	request, requestErr := http.NewRequestWithContext(ctx, "POST", c.RestEndpoint+"/hmip/auth/connectionRequest", bytes.NewReader(requestBody))
 	if requestErr != nil {
 		return requestErr
 	}
@@ -114,18 +154,19 @@ func (c *Config) connectionRequest(httpClient *http.Client) error {
 	if responseErr != nil {
 		return responseErr
 	}
+	_ = response.Body.Close()
 	if response.StatusCode != 200 {
 		return errors.New(fmt.Sprintf("Error on connection request (%s)", response.Status))
 	}
 	return nil
 }
 
-func (c *Config) requestAcknowledge(httpClient *http.Client) error {
+func (c *Config) requestAcknowledge(ctx context.Context, httpClient *http.Client) error {
 	requestBody, _ := json.Marshal(registerClientRequest{
 		DeviceID: c.DeviceID,
 	})
 	return retry.Do(func() error {
-		request, requestErr := http.NewRequest("POST", c.RestEndpoint+"/hmip/auth/isRequestAcknowledged", bytes.NewReader(requestBody))
+		request, requestErr := http.NewRequestWithContext(ctx, "POST", c.RestEndpoint+"/hmip/auth/isRequestAcknowledged", bytes.NewReader(requestBody))
 		if requestErr != nil {
 			return retry.Unrecoverable(requestErr)
 		}
@@ -133,6 +174,7 @@ func (c *Config) requestAcknowledge(httpClient *http.Client) error {
 		if responseErr != nil {
 			return retry.Unrecoverable(responseErr)
 		}
+		_ = response.Body.Close()
 		if response.StatusCode == 200 {
 			return nil
 		}
@@ -140,15 +182,15 @@ func (c *Config) requestAcknowledge(httpClient *http.Client) error {
 		if response.StatusCode == 400 {
 			return responseErr
 		}
-		return retry.Unrecoverable(requestErr)
-	}, retry.Delay(3*time.Second), retry.DelayType(retry.FixedDelay), retry.Attempts(20))
+		return retry.Unrecoverable(responseErr)
+	}, retry.Context(ctx), retry.LastErrorOnly(true), retry.Delay(3*time.Second), retry.DelayType(retry.FixedDelay), retry.Attempts(acknowledgeAttempts(ctx)))
 }
 
-func (c *Config) requestAuthToken(httpClient *http.Client) error {
+func (c *Config) requestAuthToken(ctx context.Context, httpClient *http.Client) error {
 	requestBody, _ := json.Marshal(registerClientRequest{
 		DeviceID: c.DeviceID,
 	})
-	request, requestErr := http.NewRequest("POST", c.RestEndpoint+"/hmip/auth/requestAuthToken", bytes.NewReader(requestBody))
+	request, requestErr := http.NewRequestWithContext(ctx, "POST", c.RestEndpoint+"/hmip/auth/requestAuthToken", bytes.NewReader(requestBody))
 	if requestErr != nil {
 		return requestErr
 	}
@@ -169,12 +211,12 @@ func (c *Config) requestAuthToken(httpClient *http.Client) error {
 	return nil
 }
 
-func (c *Config) confirmAuthToken(httpClient *http.Client) error {
+func (c *Config) confirmAuthToken(ctx context.Context, httpClient *http.Client) error {
 	requestBody, _ := json.Marshal(registerClientRequest{
 		DeviceID:  c.DeviceID,
 		AuthToken: c.AuthToken,
 	})
-	request, requestErr := http.NewRequest("POST", c.RestEndpoint+"/hmip/auth/confirmAuthToken", bytes.NewReader(requestBody))
+	request, requestErr := http.NewRequestWithContext(ctx, "POST", c.RestEndpoint+"/hmip/auth/confirmAuthToken", bytes.NewReader(requestBody))
 	if requestErr != nil {
 		return requestErr
 	}
@@ -195,6 +237,21 @@ func (c *Config) confirmAuthToken(httpClient *http.Client) error {
 	return nil
 }
 
+// acknowledgeAttempts returns the number of attempts waiting for the acknowledge,
+// which is unlimited when the waiting time is limited by the deadline of the context.
+func acknowledgeAttempts(ctx context.Context) uint {
+	if _, hasDeadline := ctx.Deadline(); hasDeadline {
+		return 0
+	}
+	return 20
+}
+
+func (c *Config) getDecodeOptions() DecodeOptions {
+	return DecodeOptions{
+		Lenient: c.LenientDecoding,
+	}
+}
+
 func (c *Config) getClientCharacteristics() clientCharacteristics {
 	return clientCharacteristics{
 		APIVersion: ApiVersion,
@@ -207,7 +264,7 @@ func (c *Config) getClientCharacteristics() clientCharacteristics {
 
 func (c *Config) lookupEndpoints() error {
 	requestBody, _ := json.Marshal(hostsLookupRequest{
-		AccessPointSGTIN:      c.getTrimmedAccessPointSGTIN(),
+		AccessPointSGTIN:      c.AccessPointSGTIN.Canonical(),
 		ClientCharacteristics: c.getClientCharacteristics(),
 	})
 	response, err := http.Post(c.LookupEndpoint, "application/json", bytes.NewReader(requestBody))
@@ -231,14 +288,10 @@ func (c *Config) lookupEndpoints() error {
 	return nil
 }
 
-func (c *Config) getTrimmedAccessPointSGTIN() string {
-	return strings.ReplaceAll(strings.ToUpper(c.AccessPointSGTIN), "-", "")
-}
-
 // ======================================================
 
 type hostsLookupRequest struct {
-	AccessPointSGTIN      string                `json:"id"`
+	AccessPointSGTIN      SGTIN                 `json:"id"`
 	ClientCharacteristics clientCharacteristics `json:"clientCharacteristics"`
 }
 
@@ -250,7 +303,7 @@ type hostsLookupResponse struct {
 type registerClientRequest struct {
 	DeviceID         string `json:"deviceId"`
 	DeviceName       string `json:"deviceName"`
-	AccessPointSGTIN string `json:"sgtin"`
+	AccessPointSGTIN SGTIN  `json:"sgtin"`
 	AuthToken        string `json:"authToken"`
 }
 
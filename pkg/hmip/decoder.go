@@ -0,0 +1,448 @@
+package hmip
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+// DecodeOptions control the decoding of states and events.
+type DecodeOptions struct {
+	// Lenient skips or partially decodes malformed entries instead of failing.
+	// The problems are reported as DecodeWarning. Events whose device or group
+	// is skipped are skipped as well.
+	Lenient bool
+}
+
+// DecodeWarning describes a problem with an entry that was skipped
+// or only partially decoded in lenient mode.
+type DecodeWarning struct {
+	// Path of the entry, e.g. devices.<device-id>.functionalChannels.1
+	Path string
+	// Partial is true if the entry was decoded partially, false if it was skipped
+	Partial bool
+	Err     error
+}
+
+func (w DecodeWarning) String() string {
+	if w.Partial {
+		return fmt.Sprintf("Partially decoded %s: %v", w.Path, w.Err)
+	}
+	return fmt.Sprintf("Skipped %s: %v", w.Path, w.Err)
+}
+
+var decodeWarningCount atomic.Uint64
+
+// GetDecodeWarningCount returns the number of decode warnings reported since the start of the process.
+func GetDecodeWarningCount() uint64 {
+	return decodeWarningCount.Load()
+}
+
+// ======================================================
+
+type decoder struct {
+	lenient  bool
+	warnings []DecodeWarning
+	// attributes is the rest of the block the attribute caches of the entities are taken from
+	attributes []rawAttributes
+}
+
+// rawAttributesBlock is the number of attribute caches allocated at once.
+const rawAttributesBlock = 32
+
+func newDecoder(options DecodeOptions) *decoder {
+	return &decoder{
+		lenient: options.Lenient,
+	}
+}
+
+// setRaw sets the raw JSON of the entity like raw.setRaw, but takes the attribute cache
+// from a block shared by the decoded entities instead of allocating it separately.
+func (d *decoder) setRaw(entity *raw, value json.RawMessage) {
+	if len(d.attributes) == 0 {
+		d.attributes = make([]rawAttributes, rawAttributesBlock)
+	}
+	entity.Raw = value
+	entity.attributes = &d.attributes[0]
+	d.attributes = d.attributes[1:]
+}
+
+// report handles an error decoding the entry at the path. In strict mode the error is returned,
+// in lenient mode a warning is recorded and nil is returned.
+func (d *decoder) report(path string, err error, partial bool) error {
+	if !d.lenient {
+		return err
+	}
+	d.warnings = append(d.warnings, DecodeWarning{
+		Path:    path,
+		Partial: partial,
+		Err:     err,
+	})
+	decodeWarningCount.Add(1)
+	return nil
+}
+
+// check handles the error of decoding an entry. It returns true if the entry should be kept,
+// which is the case without error or in lenient mode when the entry was decoded partially.
+func (d *decoder) check(path string, decoded bool, err error) (bool, error) {
+	if err == nil {
+		return true, nil
+	}
+	partial := decoded && isPartiallyDecoded(err)
+	return partial, d.report(path, err, partial)
+}
+
+// isPartiallyDecoded tells if json.Unmarshal decoded all other attributes despite the error,
+// which is the case if only the type of a single attribute did not match.
+func isPartiallyDecoded(err error) bool {
+	var typeErr *json.UnmarshalTypeError
+	return errors.As(err, &typeErr) && typeErr.Field != ""
+}
+
+// firstError tells if a value is still usable after a decoding error
+// and returns the first of the errors occurred while decoding the value.
+func firstError(first, err error) (bool, error) {
+	if err == nil {
+		return true, first
+	}
+	if first == nil {
+		first = err
+	}
+	return isPartiallyDecoded(err), first
+}
+
+// decodeMap calls the entry function for all members of the JSON object in document order.
+func (d *decoder) decodeMap(path string, value []byte, entry func(key string, path string, value []byte) error) error {
+	if len(value) == 0 {
+		return nil
+	}
+	scanner := newMemberScanner(value)
+	for scanner.next() {
+		// The key is taken from the path, so it is not allocated separately
+		entryPath := path + "." + string(scanner.key)
+		err := entry(entryPath[len(path)+1:], entryPath, scanner.value)
+		if err != nil {
+			return err
+		}
+	}
+	if scanner.err != nil {
+		return d.report(path, scanner.err, false)
+	}
+	return nil
+}
+
+// decodeEntity decodes the value into the entity created by a factory from the base. Entities
+// embedding the base pointer (like all built-in types) are decoded in a single pass, all others
+// (like external types embedding the interface) need the base to be decoded separately.
+func decodeEntity(value []byte, base, entity any, embedsBase bool) (bool, error) {
+	var first error
+	if !embedsBase && entity != base {
+		usable, err := firstError(nil, json.Unmarshal(value, base))
+		if !usable {
+			return false, err
+		}
+		first = err
+	}
+	return firstError(first, json.Unmarshal(value, entity))
+}
+
+// The base accessors are promoted by embedding the base pointer, so the decoder
+// can tell built-in types from external ones embedding only the interface.
+type (
+	deviceBased interface {
+		deviceBase() *device
+	}
+	channelBased interface {
+		channelBase() *functionalChannel
+	}
+	groupBased interface {
+		groupBase() *group
+	}
+)
+
+// ======================================================
+
+func (d *decoder) decodeDevices(path string, value []byte) (Devices, error) {
+	var devices Devices
+	err := d.decodeMap(path, value, func(_ string, path string, value []byte) error {
+		device, err := d.decodeDevice(path, value)
+		if device != nil {
+			devices = append(devices, device)
+		}
+		return err
+	})
+	sortByName(devices)
+	return devices, err
+}
+
+func (d *decoder) decodeDevice(path string, value []byte) (Device, error) {
+	device, err := d.decodeDeviceValue(path, value)
+	keep, err := d.check(path, device != nil, err)
+	if !keep {
+		return nil, err
+	}
+	return device, err
+}
+
+func (d *decoder) decodeDeviceValue(path string, value []byte) (Device, error) {
+	// The type and the channels are picked in one scan, the channels are decoded on their own
+	var deviceType, channels []byte
+	var channelsStart, channelsEnd int
+	var err error
+	scanner := newMemberScanner(value)
+	for scanner.next() && err == nil {
+		switch string(scanner.key) {
+		case "type":
+			deviceType, err = rawString("type", scanner.value)
+		case "functionalChannels":
+			channels = scanner.value
+			channelsStart, channelsEnd = scanner.start, scanner.pos
+		}
+	}
+	if scanner.err != nil {
+		err = scanner.err
+	}
+	if err != nil {
+		return nil, err
+	}
+	// The type is set by decoding the device, so it is only used to look up the factory here
+	base := &device{}
+	d.setRaw(&base.raw, value)
+	registryLock.RLock()
+	factory, registered := deviceFactories[string(deviceType)]
+	registryLock.RUnlock()
+	var device Device = base
+	if registered {
+		device = factory(base)
+	}
+	// The device is decoded without the channels, so they are not scanned a second time
+	attributes := value
+	if channels != nil {
+		buffer := objectBuffers.Get().(*[]byte)
+		defer objectBuffers.Put(buffer)
+		*buffer = withoutMember((*buffer)[:0], value, channelsStart, channelsEnd)
+		attributes = *buffer
+	}
+	embedded, embedsBase := device.(deviceBased)
+	usable, first := decodeEntity(attributes, base, device, embedsBase && embedded.deviceBase() == base)
+	if !usable {
+		return nil, first
+	}
+	// The channels refer to the device returned by the factory, not to the base device
+	base.FunctionalChannels, err = d.decodeChannels(path+".functionalChannels", channels, device)
+	if err != nil {
+		return nil, err
+	}
+	return device, first
+}
+
+// objectBuffers holds the buffers for objects with members cut out. Decoding copies
+// all values out of the buffer, so it can be reused right after.
+var objectBuffers = sync.Pool{
+	New: func() any {
+		return new([]byte)
+	},
+}
+
+func (d *decoder) decodeChannels(path string, value []byte, device Device) (FunctionalChannels, error) {
+	var channels FunctionalChannels
+	err := d.decodeMap(path, value, func(_ string, path string, value []byte) error {
+		channel, err := d.decodeChannel(path, value, device)
+		if channel != nil {
+			channels = append(channels, channel)
+		}
+		return err
+	})
+	slices.SortStableFunc(channels, func(a, b FunctionalChannel) int {
+		return cmp.Compare(a.GetIndex(), b.GetIndex())
+	})
+	return channels, err
+}
+
+func (d *decoder) decodeChannel(path string, value []byte, device Device) (FunctionalChannel, error) {
+	channel, err := d.decodeChannelValue(value, device)
+	keep, err := d.check(path, channel != nil, err)
+	if !keep {
+		return nil, err
+	}
+	return channel, err
+}
+
+func (d *decoder) decodeChannelValue(value []byte, device Device) (FunctionalChannel, error) {
+	// The type is set by decoding the channel, so it is only used to look up the factory here
+	channelType, err := peekRawString(value, "functionalChannelType")
+	if err != nil {
+		return nil, err
+	}
+	base := &functionalChannel{
+		device: device,
+	}
+	d.setRaw(&base.raw, value)
+	registryLock.RLock()
+	factory, registered := channelFactories[string(channelType)]
+	registryLock.RUnlock()
+	var channel FunctionalChannel = base
+	if registered {
+		channel = factory(base)
+	}
+	embedded, embedsBase := channel.(channelBased)
+	usable, first := decodeEntity(value, base, channel, embedsBase && embedded.channelBase() == base)
+	if !usable {
+		return nil, first
+	}
+	return channel, first
+}
+
+func (d *decoder) decodeGroups(path string, value []byte) (Groups, error) {
+	var groups Groups
+	err := d.decodeMap(path, value, func(_ string, path string, value []byte) error {
+		group, err := d.decodeGroup(path, value)
+		if group != nil {
+			groups = append(groups, group)
+		}
+		return err
+	})
+	sortByName(groups)
+	return groups, err
+}
+
+func (d *decoder) decodeGroup(path string, value []byte) (Group, error) {
+	group, err := d.decodeGroupValue(value)
+	keep, err := d.check(path, group != nil, err)
+	if !keep {
+		return nil, err
+	}
+	return group, err
+}
+
+func (d *decoder) decodeGroupValue(value []byte) (Group, error) {
+	// The type is set by decoding the group, so it is only used to look up the factory here
+	groupType, err := peekRawString(value, "type")
+	if err != nil {
+		return nil, err
+	}
+	base := &group{}
+	d.setRaw(&base.raw, value)
+	registryLock.RLock()
+	factory, registered := groupFactories[string(groupType)]
+	registryLock.RUnlock()
+	var group Group = base
+	if registered {
+		group = factory(base)
+	}
+	embedded, embedsBase := group.(groupBased)
+	usable, first := decodeEntity(value, base, group, embedsBase && embedded.groupBase() == base)
+	if !usable {
+		return nil, first
+	}
+	return group, first
+}
+
+func (d *decoder) decodeClients(path string, value []byte) (Clients, error) {
+	var clients Clients
+	err := d.decodeMap(path, value, func(_ string, path string, value []byte) error {
+		client := &client{}
+		d.setRaw(&client.raw, value)
+		keep, err := d.check(path, true, json.Unmarshal(value, client))
+		if keep {
+			clients = append(clients, client)
+		}
+		return err
+	})
+	sortByName(clients)
+	return clients, err
+}
+
+func (d *decoder) decodeHome(path string, value []byte) (*home, error) {
+	if len(value) == 0 || string(value) == "null" {
+		return nil, nil
+	}
+	home := &home{}
+	keep, err := d.check(path, true, home.decode(value))
+	if !keep {
+		return nil, err
+	}
+	return home, err
+}
+
+// decodeEvents decodes the events ordered by their numeric keys, which give the order
+// in which the events occurred. Other keys follow in document order.
+func (d *decoder) decodeEvents(path string, value []byte) (Events, error) {
+	type indexedEvent struct {
+		index int
+		event Event
+	}
+	var events []indexedEvent
+	err := d.decodeMap(path, value, func(key string, path string, value []byte) error {
+		event, err := d.decodeEvent(path, value)
+		if event != nil {
+			index, convErr := strconv.Atoi(key)
+			if convErr != nil {
+				index = math.MaxInt
+			}
+			events = append(events, indexedEvent{index: index, event: event})
+		}
+		return err
+	})
+	slices.SortStableFunc(events, func(a, b indexedEvent) int {
+		return cmp.Compare(a.index, b.index)
+	})
+	result := make(Events, 0, len(events))
+	for _, event := range events {
+		result = append(result, event.event)
+	}
+	return result, err
+}
+
+func (d *decoder) decodeEvent(path string, value []byte) (Event, error) {
+	warnings := len(d.warnings)
+	event, base, err := d.decodeEventValue(path, value)
+	keep, err := d.check(path, event != nil, err)
+	if !keep {
+		return nil, err
+	}
+	// All warnings reported while decoding the event belong to the event
+	base.warnings = d.warnings[warnings:len(d.warnings):len(d.warnings)]
+	return event, err
+}
+
+func (d *decoder) decodeEventValue(path string, value []byte) (Event, *event, error) {
+	eventType, err := peekString(value, "pushEventType")
+	if err != nil {
+		return nil, nil, err
+	}
+	// The base event consists of the type only, so it is complete without decoding
+	base := &event{
+		Type: eventType,
+	}
+	d.setRaw(&base.raw, value)
+	registryLock.RLock()
+	factory, registered := eventFactories[eventType]
+	registryLock.RUnlock()
+	if !registered {
+		return base, base, nil
+	}
+	event := factory(base)
+	usable, first := true, error(nil)
+	if nested, ok := event.(nestedDecoding); ok {
+		usable, first = firstError(nil, nested.decode(d, path, value))
+	} else if event != Event(base) {
+		usable, first = firstError(nil, json.Unmarshal(value, event))
+	}
+	if !usable {
+		return nil, nil, first
+	}
+	return event, base, first
+}
+
+// nestedDecoding is implemented by entities containing other entities,
+// which have to be decoded with the same decoder.
+type nestedDecoding interface {
+	decode(d *decoder, path string, value []byte) error
+}
@@ -0,0 +1,354 @@
+package hmip
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+	"unsafe"
+)
+
+// TestParseStateMatchesEncodingJSON compares the single pass decoder with decoding
+// every entity by encoding/json, the way states were decoded before.
+func TestParseStateMatchesEncodingJSON(t *testing.T) {
+	data := readFixture(t, "state.json")
+	expected, err := decodeStateByEncodingJSON(data)
+	if err != nil {
+		t.Fatalf("unexpected error of encoding/json: %v", err)
+	}
+
+	actual, err := ParseState(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	assertSameFields(t, "home", expected.home, actual.GetHome())
+	assertSameEntities(t, "devices", expected.devices, actual.GetDevices())
+	assertSameEntities(t, "groups", expected.groups, actual.GetGroups())
+	assertSameEntities(t, "clients", expected.clients, actual.GetClients())
+	for _, device := range actual.GetDevices() {
+		for _, channel := range device.GetFunctionalChannels() {
+			if channel.GetDevice() != device {
+				t.Errorf("expected channel %d of device %s to refer to the device", channel.GetIndex(), device.GetID())
+			}
+		}
+	}
+}
+
+func assertSameEntities[T identifiable](t *testing.T, name string, expected, actual []T) {
+	t.Helper()
+	if len(expected) != len(actual) {
+		t.Fatalf("expected %d %s, got %d", len(expected), name, len(actual))
+	}
+	for i := range expected {
+		assertSameFields(t, fmt.Sprintf("%s.%s", name, expected[i].GetID()), expected[i], actual[i])
+	}
+}
+
+func assertSameFields(t *testing.T, name string, expected, actual any) {
+	t.Helper()
+	expectedFields := exportedFields(reflect.ValueOf(expected))
+	actualFields := exportedFields(reflect.ValueOf(actual))
+	if !reflect.DeepEqual(expectedFields, actualFields) {
+		t.Errorf("%s differs\nexpected: %v\nactual:   %v", name, expectedFields, actualFields)
+	}
+}
+
+// exportedFields collects the exported fields of the value including the fields of embedded
+// types. Unexported fields like the references to devices and states are skipped.
+func exportedFields(value reflect.Value) any {
+	if !value.CanInterface() && value.CanAddr() {
+		value = reflect.NewAt(value.Type(), unsafe.Pointer(value.UnsafeAddr())).Elem()
+	}
+	switch value.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if value.IsNil() {
+			return nil
+		}
+		return exportedFields(value.Elem())
+	case reflect.Struct:
+		if value.Type() == reflect.TypeOf(time.Time{}) {
+			return value.Interface()
+		}
+		fields := make(map[string]any)
+		for i := 0; i < value.NumField(); i++ {
+			field := value.Type().Field(i)
+			if field.Anonymous {
+				if embedded, ok := exportedFields(value.Field(i)).(map[string]any); ok {
+					maps.Copy(fields, embedded)
+					continue
+				}
+			}
+			if field.IsExported() || field.Anonymous {
+				fields[field.Name] = exportedFields(value.Field(i))
+			}
+		}
+		return fields
+	case reflect.Slice:
+		if value.IsNil() {
+			return nil
+		}
+		if value.Type().Elem().Kind() == reflect.Uint8 {
+			return string(value.Bytes())
+		}
+		elements := make([]any, value.Len())
+		for i := range elements {
+			elements[i] = exportedFields(value.Index(i))
+		}
+		return elements
+	case reflect.Map:
+		if value.IsNil() {
+			return nil
+		}
+		entries := make(map[string]any, value.Len())
+		for _, key := range value.MapKeys() {
+			entries[fmt.Sprint(key.Interface())] = exportedFields(value.MapIndex(key))
+		}
+		return entries
+	default:
+		return value.Interface()
+	}
+}
+
+// ======================================================
+
+type encodingJSONState struct {
+	home    *home
+	devices Devices
+	groups  Groups
+	clients Clients
+}
+
+// decodeStateByEncodingJSON decodes the state into maps of raw values by encoding/json
+// and every entity with a first pass for its type and a second pass for its attributes.
+func decodeStateByEncodingJSON(data []byte) (*encodingJSONState, error) {
+	var wire struct {
+		Home    json.RawMessage            `json:"home"`
+		Devices map[string]json.RawMessage `json:"devices"`
+		Groups  map[string]json.RawMessage `json:"groups"`
+		Clients map[string]json.RawMessage `json:"clients"`
+	}
+	err := json.Unmarshal(data, &wire)
+	if err != nil {
+		return nil, err
+	}
+	s := &encodingJSONState{home: &home{}}
+	err = s.home.UnmarshalJSON(wire.Home)
+	if err != nil {
+		return nil, err
+	}
+	for _, value := range wire.Devices {
+		device, err := decodeDeviceByEncodingJSON(value)
+		if err != nil {
+			return nil, err
+		}
+		s.devices = append(s.devices, device)
+	}
+	for _, value := range wire.Groups {
+		var header struct {
+			Type string `json:"type"`
+		}
+		err = json.Unmarshal(value, &header)
+		if err != nil {
+			return nil, err
+		}
+		base := &group{}
+		base.Raw = value
+		var group Group = base
+		if factory, registered := groupFactories[header.Type]; registered {
+			group = factory(base)
+		}
+		err = decodeTwice(value, base, group)
+		if err != nil {
+			return nil, err
+		}
+		s.groups = append(s.groups, group)
+	}
+	for _, value := range wire.Clients {
+		client := &client{}
+		client.Raw = value
+		err = json.Unmarshal(value, client)
+		if err != nil {
+			return nil, err
+		}
+		s.clients = append(s.clients, client)
+	}
+	sortByName(s.devices)
+	sortByName(s.groups)
+	sortByName(s.clients)
+	return s, nil
+}
+
+func decodeDeviceByEncodingJSON(value json.RawMessage) (Device, error) {
+	var header struct {
+		Type               string                     `json:"type"`
+		FunctionalChannels map[string]json.RawMessage `json:"functionalChannels"`
+	}
+	err := json.Unmarshal(value, &header)
+	if err != nil {
+		return nil, err
+	}
+	base := &device{}
+	base.Raw = value
+	var device Device = base
+	if factory, registered := deviceFactories[header.Type]; registered {
+		device = factory(base)
+	}
+	err = decodeTwice(value, base, device)
+	if err != nil {
+		return nil, err
+	}
+	for _, channelValue := range header.FunctionalChannels {
+		var channelHeader struct {
+			Type string `json:"functionalChannelType"`
+		}
+		err = json.Unmarshal(channelValue, &channelHeader)
+		if err != nil {
+			return nil, err
+		}
+		channelBase := &functionalChannel{device: device}
+		channelBase.Raw = channelValue
+		var channel FunctionalChannel = channelBase
+		if factory, registered := channelFactories[channelHeader.Type]; registered {
+			channel = factory(channelBase)
+		}
+		err = decodeTwice(channelValue, channelBase, channel)
+		if err != nil {
+			return nil, err
+		}
+		base.FunctionalChannels = append(base.FunctionalChannels, channel)
+	}
+	slices.SortFunc(base.FunctionalChannels, func(a, b FunctionalChannel) int {
+		return cmp.Compare(a.GetIndex(), b.GetIndex())
+	})
+	return device, nil
+}
+
+func decodeTwice(value []byte, base, entity any) error {
+	err := json.Unmarshal(value, base)
+	if err != nil || entity == base {
+		return err
+	}
+	return json.Unmarshal(value, entity)
+}
+
+func TestParseStateLenient(t *testing.T) {
+	data := []byte(`{
+		"devices":{
+			"broken":"device",
+			"device-1":{"id":"device-1","type":"PLUGABLE_SWITCH","label":7,"functionalChannels":{
+				"0":{"functionalChannelType":"DEVICE_BASE","index":0},
+				"1":["broken channel"],
+				"2":{"functionalChannelType":"SWITCH_CHANNEL","index":2,"on":true}}}},
+		"groups":{
+			"broken":[],
+			"group-1":{"id":"group-1","type":"META","label":"Kitchen","channels":[]}},
+		"clients":{
+			"broken":1,
+			"client-1":{"id":"client-1","label":"App"}}}`)
+
+	if _, err := ParseState(data); err == nil {
+		t.Fatal("expected error in strict mode")
+	}
+
+	count := GetDecodeWarningCount()
+	state, err := ParseStateWithOptions(data, DecodeOptions{Lenient: true})
+	if err != nil {
+		t.Fatalf("unexpected error in lenient mode: %v", err)
+	}
+	expected := []struct {
+		path    string
+		partial bool
+	}{
+		{"devices.broken", false},
+		{"devices.device-1.functionalChannels.1", false},
+		{"devices.device-1", true},
+		{"groups.broken", false},
+		{"clients.broken", false},
+	}
+	warnings := state.GetDecodeWarnings()
+	if len(warnings) != len(expected) {
+		t.Fatalf("expected %d warnings, got %v", len(expected), warnings)
+	}
+	for i, warning := range warnings {
+		if warning.Path != expected[i].path || warning.Partial != expected[i].partial || warning.Err == nil {
+			t.Errorf("expected warning for %s (partial %t), got %v", expected[i].path, expected[i].partial, warning)
+		}
+	}
+	if GetDecodeWarningCount()-count != uint64(len(expected)) {
+		t.Errorf("expected the warning count to grow by %d, got %d", len(expected), GetDecodeWarningCount()-count)
+	}
+
+	if len(state.GetDevices()) != 1 || len(state.GetGroups()) != 1 || len(state.GetClients()) != 1 {
+		t.Fatal("expected the malformed devices, groups and clients to be skipped")
+	}
+	device := state.GetDeviceByID("device-1")
+	if device.GetType() != "PLUGABLE_SWITCH" || len(device.GetFunctionalChannels()) != 2 {
+		t.Errorf("expected the partially decoded device without the malformed channel, got %+v", device)
+	}
+	if _, ok := FirstChannel[SwitchChannel](device); !ok {
+		t.Error("expected the switch channel to be decoded")
+	}
+}
+
+func TestDecodePushMessageLenient(t *testing.T) {
+	data := []byte(`{
+		"events":{
+			"0":{"pushEventType":"DEVICE_CHANGED","device":"broken"},
+			"1":{"pushEventType":"GROUP_CHANGED","group":{"id":"group-1","type":"META","label":"Kitchen","channels":[]}},
+			"2":{"pushEventType":"DEVICE_CHANGED","device":{"id":"device-1","type":"PLUGABLE_SWITCH","label":"Switch","functionalChannels":{
+				"1":{"functionalChannelType":"SWITCH_CHANNEL","index":1,"on":"yes"}}}},
+			"3":{"pushEventType":"GROUP_CHANGED","group":1}},
+		"origin":{"originType":"DEVICE","id":"device-1"}}`)
+
+	if _, _, err := decodePushMessage(data, DecodeOptions{}); err == nil {
+		t.Fatal("expected error in strict mode")
+	}
+
+	count := GetDecodeWarningCount()
+	message, warnings, err := decodePushMessage(data, DecodeOptions{Lenient: true})
+	if err != nil {
+		t.Fatalf("unexpected error in lenient mode: %v", err)
+	}
+	if len(message.Events) != 2 {
+		t.Fatalf("expected the events with skipped device and group to be dropped, got %d events", len(message.Events))
+	}
+	group, ok := message.Events[0].(GroupChangedEvent)
+	if !ok || group.GetGroup() == nil || group.GetGroup().GetID() != "group-1" || len(group.GetDecodeWarnings()) != 0 {
+		t.Errorf("expected the group changed event without warnings, got %+v", message.Events[0])
+	}
+	device, ok := message.Events[1].(DeviceChangedEvent)
+	if !ok || device.GetDevice() == nil {
+		t.Fatalf("expected the device changed event with the device, got %+v", message.Events[1])
+	}
+	eventWarnings := device.GetDecodeWarnings()
+	if len(eventWarnings) != 1 || !eventWarnings[0].Partial || eventWarnings[0].Path != "events.2.device.functionalChannels.1" {
+		t.Errorf("expected the partially decoded channel as warning of the event, got %v", eventWarnings)
+	}
+
+	expected := []struct {
+		path    string
+		partial bool
+	}{
+		{"events.0.device", false},
+		{"events.0", false},
+		{"events.2.device.functionalChannels.1", true},
+		{"events.3.group", false},
+		{"events.3", false},
+	}
+	if len(warnings) != len(expected) {
+		t.Fatalf("expected %d warnings, got %v", len(expected), warnings)
+	}
+	for i, warning := range warnings {
+		if warning.Path != expected[i].path || warning.Partial != expected[i].partial {
+			t.Errorf("expected warning for %s (partial %t), got %v", expected[i].path, expected[i].partial, warning)
+		}
+	}
+	if GetDecodeWarningCount()-count != uint64(len(expected)) {
+		t.Errorf("expected the warning count to grow by %d, got %d", len(expected), GetDecodeWarningCount()-count)
+	}
+}
@@ -1,23 +1,33 @@
 package hmip
 
-import "encoding/json"
+import "bytes"
 
 type device struct {
+	raw
 	stateful
 	named
 	typed
-	Model                string             `json:"modelType"`
-	SGTIN                string             `json:"serializedGlobalTradeItemNumber"`
-	FunctionalChannels   FunctionalChannels `json:"functionalChannels"`
-	PermanentlyReachable bool               `json:"permanentlyReachable"`
-	ConnectionType       string             `json:"connectionType"`
+	Model                        string                    `json:"modelType"`
+	SGTIN                        SGTIN                     `json:"serializedGlobalTradeItemNumber"`
+	FunctionalChannels           FunctionalChannels        `json:"-"`
+	PermanentlyReachable         bool                      `json:"permanentlyReachable"`
+	ConnectionType               string                    `json:"connectionType"`
+	FirmwareVersion              string                    `json:"firmwareVersion"`
+	FirmwareVersionInteger       int                       `json:"firmwareVersionInteger"`
+	AvailableFirmwareVersion     string                    `json:"availableFirmwareVersion"`
+	UpdateState                  string                    `json:"updateState"`
+	LiveUpdateState              string                    `json:"liveUpdateState"`
+	ManufacturerCode             int                       `json:"manufacturerCode"`
+	OEM                          string                    `json:"oem"`
+	MeasuredAttributes           map[string]map[string]any `json:"measuredAttributes"`
+	AutomaticValveAdaptionNeeded bool                      `json:"automaticValveAdaptionNeeded"`
 }
 
 func (d device) GetModel() string {
 	return d.Model
 }
 
-func (d device) GetSGTIN() string {
+func (d device) GetSGTIN() SGTIN {
 	return d.SGTIN
 }
 
@@ -29,6 +39,46 @@ func (d device) GetConnectionType() string {
 	return d.ConnectionType
 }
 
+func (d device) GetFirmwareVersion() string {
+	return d.FirmwareVersion
+}
+
+func (d device) GetFirmwareVersionInteger() int {
+	return d.FirmwareVersionInteger
+}
+
+func (d device) GetAvailableFirmwareVersion() string {
+	return d.AvailableFirmwareVersion
+}
+
+func (d device) GetUpdateState() string {
+	return d.UpdateState
+}
+
+func (d device) GetLiveUpdateState() string {
+	return d.LiveUpdateState
+}
+
+func (d device) IsUpdateAvailable() bool {
+	return d.UpdateState == DEVICE_UPDATE_STATE_UPDATE_AVAILABLE || d.LiveUpdateState == LIVE_UPDATE_STATE_UPDATE_AVAILABLE
+}
+
+func (d device) GetManufacturerCode() int {
+	return d.ManufacturerCode
+}
+
+func (d device) GetOEM() string {
+	return d.OEM
+}
+
+func (d device) GetMeasuredAttributes() map[string]map[string]any {
+	return d.MeasuredAttributes
+}
+
+func (d device) IsAutomaticValveAdaptionNeeded() bool {
+	return d.AutomaticValveAdaptionNeeded
+}
+
 func (d device) GetFunctionalChannels() FunctionalChannels {
 	var channels FunctionalChannels
 	for _, channel := range d.FunctionalChannels {
@@ -37,6 +87,15 @@ func (d device) GetFunctionalChannels() FunctionalChannels {
 	return channels
 }
 
+func (d device) GetChannel(index int) FunctionalChannel {
+	for _, channel := range d.FunctionalChannels {
+		if channel.GetIndex() == index {
+			return channel
+		}
+	}
+	return nil
+}
+
 func (d device) GetFunctionalChannelsByType(channelType string) FunctionalChannels {
 	var channels FunctionalChannels
 	for _, channel := range d.FunctionalChannels {
@@ -47,23 +106,17 @@ func (d device) GetFunctionalChannelsByType(channelType string) FunctionalChanne
 	return channels
 }
 
+func (d *device) deviceBase() *device {
+	return d
+}
+
 // ======================================================
 
 func (d *Devices) UnmarshalJSON(value []byte) error {
-	var deviceValues map[string]json.RawMessage
-	err := json.Unmarshal(value, &deviceValues)
+	devices, err := newDecoder(DecodeOptions{}).decodeDevices("devices", bytes.Clone(value))
 	if err != nil {
 		return err
 	}
-	devices := make(Devices, 0, len(deviceValues))
-	for _, deviceValue := range deviceValues {
-		var device device
-		err = json.Unmarshal(deviceValue, &device)
-		if err != nil {
-			return err
-		}
-		devices = append(devices, &device)
-	}
 	*d = devices
 	return nil
 }
@@ -0,0 +1,347 @@
+package hmip
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// StateDiff describes the differences between two states, e.g. before and after a reconnect.
+// Channels are listed separately for devices contained in both states, the channels of
+// added and removed devices are not listed.
+type StateDiff struct {
+	Devices  []EntityDiff  `json:"devices,omitempty"`
+	Groups   []EntityDiff  `json:"groups,omitempty"`
+	Clients  []EntityDiff  `json:"clients,omitempty"`
+	Channels []ChannelDiff `json:"channels,omitempty"`
+}
+
+// EntityDiff describes an added, removed or changed device, group or client.
+type EntityDiff struct {
+	ID string `json:"id"`
+	// Change is one of CHANGE_TYPE_ADDED, CHANGE_TYPE_REMOVED or CHANGE_TYPE_CHANGED
+	Change string `json:"change"`
+	// Name is the current name or the last name of a removed entity
+	Name    string      `json:"name"`
+	Renamed bool        `json:"renamed,omitempty"`
+	OldName string      `json:"oldName,omitempty"`
+	Fields  []FieldDiff `json:"fields,omitempty"`
+	// JoinedGroups and LeftGroups contain the IDs of the groups a device was added to or removed from
+	JoinedGroups []string `json:"joinedGroups,omitempty"`
+	LeftGroups   []string `json:"leftGroups,omitempty"`
+}
+
+// ChannelDiff describes an added, removed or changed functional channel of a device.
+type ChannelDiff struct {
+	DeviceID   string `json:"deviceId"`
+	DeviceName string `json:"deviceName"`
+	Index      int    `json:"index"`
+	// Change is one of CHANGE_TYPE_ADDED, CHANGE_TYPE_REMOVED or CHANGE_TYPE_CHANGED
+	Change   string      `json:"change"`
+	Label    string      `json:"label"`
+	Renamed  bool        `json:"renamed,omitempty"`
+	OldLabel string      `json:"oldLabel,omitempty"`
+	Fields   []FieldDiff `json:"fields,omitempty"`
+}
+
+// FieldDiff describes a changed attribute by its dotted path in the raw JSON (see Raw.GetAttribute).
+// The value of an added attribute is nil in Old, the value of a removed attribute is nil in New.
+type FieldDiff struct {
+	Path string `json:"path"`
+	Old  any    `json:"old"`
+	New  any    `json:"new"`
+}
+
+// DefaultIgnoredAttributes contains the attributes ignored by Diff, which are timestamps
+// changing with every status report without a change of the entity.
+var DefaultIgnoredAttributes = []string{"lastStatusUpdate", "lastSeenAtTimestamp"}
+
+// DiffOptions control the comparison of states.
+type DiffOptions struct {
+	// IgnoredAttributes contains the keys of attributes skipped on all levels of the raw JSON
+	IgnoredAttributes []string
+}
+
+// Diff compares two states by the IDs of their entities and by their raw JSON,
+// ignoring the DefaultIgnoredAttributes. A nil state is treated as empty state.
+func Diff(old, new State) StateDiff {
+	return DiffWithOptions(old, new, DiffOptions{IgnoredAttributes: DefaultIgnoredAttributes})
+}
+
+// DiffWithOptions compares two states like Diff using the given options.
+func DiffWithOptions(old, new State, options DiffOptions) StateDiff {
+	ignored := options.IgnoredAttributes
+	diff := StateDiff{}
+	diff.Devices = diffEntities(devicesOf(old), devicesOf(new), func(entityDiff *EntityDiff, oldDevice, newDevice Device) {
+		entityDiff.Fields = diffRaw(oldDevice.GetRaw(), newDevice.GetRaw(), append(slices.Clip(ignored), "functionalChannels"))
+		entityDiff.JoinedGroups, entityDiff.LeftGroups = diffIDs(groupIDsOf(old, oldDevice), groupIDsOf(new, newDevice))
+		diff.Channels = append(diff.Channels, diffChannels(oldDevice, newDevice, ignored)...)
+	})
+	diff.Groups = diffEntities(groupsOf(old), groupsOf(new), func(entityDiff *EntityDiff, oldGroup, newGroup Group) {
+		entityDiff.Fields = diffRaw(oldGroup.GetRaw(), newGroup.GetRaw(), ignored)
+	})
+	diff.Clients = diffEntities(clientsOf(old), clientsOf(new), func(entityDiff *EntityDiff, oldClient, newClient Client) {
+		entityDiff.Fields = diffRaw(oldClient.GetRaw(), newClient.GetRaw(), ignored)
+	})
+	return diff
+}
+
+// IsEmpty reports whether the states do not differ.
+func (d StateDiff) IsEmpty() bool {
+	return len(d.Devices) == 0 && len(d.Groups) == 0 && len(d.Clients) == 0 && len(d.Channels) == 0
+}
+
+// String renders the differences line by line, prefixed with + for added,
+// - for removed and ~ for changed entities.
+func (d StateDiff) String() string {
+	var builder strings.Builder
+	for _, entityDiff := range d.Devices {
+		entityDiff.write(&builder, "device")
+	}
+	for _, channelDiff := range d.Channels {
+		channelDiff.write(&builder)
+	}
+	for _, entityDiff := range d.Groups {
+		entityDiff.write(&builder, "group")
+	}
+	for _, entityDiff := range d.Clients {
+		entityDiff.write(&builder, "client")
+	}
+	return builder.String()
+}
+
+func (d EntityDiff) write(builder *strings.Builder, kind string) {
+	_, _ = fmt.Fprintf(builder, "%s %s %q (%s)", changeSymbol(d.Change), kind, d.Name, d.ID)
+	if d.Renamed {
+		_, _ = fmt.Fprintf(builder, " renamed from %q", d.OldName)
+	}
+	builder.WriteString("\n")
+	writeFields(builder, d.Fields)
+	if len(d.JoinedGroups) > 0 {
+		_, _ = fmt.Fprintf(builder, "    joined groups: %s\n", strings.Join(d.JoinedGroups, ", "))
+	}
+	if len(d.LeftGroups) > 0 {
+		_, _ = fmt.Fprintf(builder, "    left groups: %s\n", strings.Join(d.LeftGroups, ", "))
+	}
+}
+
+func (d ChannelDiff) write(builder *strings.Builder) {
+	_, _ = fmt.Fprintf(builder, "%s channel %d", changeSymbol(d.Change), d.Index)
+	if d.Label != "" {
+		_, _ = fmt.Fprintf(builder, " %q", d.Label)
+	}
+	_, _ = fmt.Fprintf(builder, " of device %q (%s)", d.DeviceName, d.DeviceID)
+	if d.Renamed {
+		_, _ = fmt.Fprintf(builder, " renamed from %q", d.OldLabel)
+	}
+	builder.WriteString("\n")
+	writeFields(builder, d.Fields)
+}
+
+func writeFields(builder *strings.Builder, fields []FieldDiff) {
+	for _, field := range fields {
+		_, _ = fmt.Fprintf(builder, "    %s: %s -> %s\n", field.Path, formatValue(field.Old), formatValue(field.New))
+	}
+}
+
+func formatValue(value any) string {
+	formatted, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(formatted)
+}
+
+func changeSymbol(change string) string {
+	switch change {
+	case CHANGE_TYPE_ADDED:
+		return "+"
+	case CHANGE_TYPE_REMOVED:
+		return "-"
+	default:
+		return "~"
+	}
+}
+
+// ======================================================
+
+// diffEntities lists the added, changed and removed entities in the order of the states.
+// The compare function fills in the differences of entities contained in both states.
+func diffEntities[T rawIdentifiable](oldEntities, newEntities []T, compare func(diff *EntityDiff, oldEntity, newEntity T)) []EntityDiff {
+	oldByID := make(map[string]T, len(oldEntities))
+	for _, entity := range oldEntities {
+		oldByID[entity.GetID()] = entity
+	}
+	newIDs := make(map[string]bool, len(newEntities))
+	var diffs []EntityDiff
+	for _, entity := range newEntities {
+		newIDs[entity.GetID()] = true
+		oldEntity, found := oldByID[entity.GetID()]
+		if !found {
+			diffs = append(diffs, EntityDiff{
+				ID:     entity.GetID(),
+				Change: CHANGE_TYPE_ADDED,
+				Name:   entity.GetName(),
+			})
+			continue
+		}
+		diff := EntityDiff{
+			ID:      entity.GetID(),
+			Change:  CHANGE_TYPE_CHANGED,
+			Name:    entity.GetName(),
+			Renamed: oldEntity.GetName() != entity.GetName(),
+		}
+		if diff.Renamed {
+			diff.OldName = oldEntity.GetName()
+		}
+		compare(&diff, oldEntity, entity)
+		if diff.Renamed || len(diff.Fields) > 0 || len(diff.JoinedGroups) > 0 || len(diff.LeftGroups) > 0 {
+			diffs = append(diffs, diff)
+		}
+	}
+	for _, entity := range oldEntities {
+		if !newIDs[entity.GetID()] {
+			diffs = append(diffs, EntityDiff{
+				ID:     entity.GetID(),
+				Change: CHANGE_TYPE_REMOVED,
+				Name:   entity.GetName(),
+			})
+		}
+	}
+	return diffs
+}
+
+// diffChannels compares the channels of a device contained in both states by their index.
+func diffChannels(oldDevice, newDevice Device, ignored []string) []ChannelDiff {
+	var diffs []ChannelDiff
+	newChannel := func(channel FunctionalChannel, change string) ChannelDiff {
+		return ChannelDiff{
+			DeviceID:   newDevice.GetID(),
+			DeviceName: newDevice.GetName(),
+			Index:      channel.GetIndex(),
+			Change:     change,
+			Label:      channel.GetLabel(),
+		}
+	}
+	for _, channel := range newDevice.GetFunctionalChannels() {
+		oldChannel := oldDevice.GetChannel(channel.GetIndex())
+		if oldChannel == nil {
+			diffs = append(diffs, newChannel(channel, CHANGE_TYPE_ADDED))
+			continue
+		}
+		diff := newChannel(channel, CHANGE_TYPE_CHANGED)
+		diff.Renamed = oldChannel.GetLabel() != channel.GetLabel()
+		if diff.Renamed {
+			diff.OldLabel = oldChannel.GetLabel()
+		}
+		diff.Fields = diffRaw(oldChannel.GetRaw(), channel.GetRaw(), ignored)
+		if diff.Renamed || len(diff.Fields) > 0 {
+			diffs = append(diffs, diff)
+		}
+	}
+	for _, channel := range oldDevice.GetFunctionalChannels() {
+		if newDevice.GetChannel(channel.GetIndex()) == nil {
+			diffs = append(diffs, newChannel(channel, CHANGE_TYPE_REMOVED))
+		}
+	}
+	return diffs
+}
+
+// diffRaw compares the attributes of the raw JSON by their dotted paths. Objects are compared
+// attribute by attribute, all other values including arrays as a whole. Attributes with the
+// ignored keys are skipped on all levels.
+func diffRaw(oldRaw, newRaw json.RawMessage, ignored []string) []FieldDiff {
+	oldFields := flattenRaw(oldRaw, ignored)
+	newFields := flattenRaw(newRaw, ignored)
+	paths := make([]string, 0, len(newFields))
+	for path := range newFields {
+		paths = append(paths, path)
+	}
+	for path := range oldFields {
+		if _, found := newFields[path]; !found {
+			paths = append(paths, path)
+		}
+	}
+	slices.Sort(paths)
+	var diffs []FieldDiff
+	for _, path := range paths {
+		if !reflect.DeepEqual(oldFields[path], newFields[path]) {
+			diffs = append(diffs, FieldDiff{
+				Path: path,
+				Old:  oldFields[path],
+				New:  newFields[path],
+			})
+		}
+	}
+	return diffs
+}
+
+func flattenRaw(raw json.RawMessage, ignored []string) map[string]any {
+	var attributes map[string]any
+	if json.Unmarshal(raw, &attributes) != nil {
+		return nil
+	}
+	fields := make(map[string]any)
+	flatten(fields, "", attributes, ignored)
+	return fields
+}
+
+func flatten(fields map[string]any, prefix string, attributes map[string]any, ignored []string) {
+	for key, value := range attributes {
+		if slices.Contains(ignored, key) {
+			continue
+		}
+		if object, ok := value.(map[string]any); ok && len(object) > 0 {
+			flatten(fields, prefix+key+".", object, ignored)
+		} else {
+			fields[prefix+key] = value
+		}
+	}
+}
+
+// diffIDs returns the IDs only contained in the new IDs and the IDs only contained in the old IDs.
+func diffIDs(oldIDs, newIDs []string) ([]string, []string) {
+	var added, removed []string
+	for _, id := range newIDs {
+		if !slices.Contains(oldIDs, id) {
+			added = append(added, id)
+		}
+	}
+	for _, id := range oldIDs {
+		if !slices.Contains(newIDs, id) {
+			removed = append(removed, id)
+		}
+	}
+	return added, removed
+}
+
+func groupIDsOf(state State, device Device) []string {
+	var ids []string
+	for _, group := range state.GetGroupsOfDevice(device.GetID()) {
+		ids = append(ids, group.GetID())
+	}
+	return ids
+}
+
+func devicesOf(state State) Devices {
+	if state == nil {
+		return nil
+	}
+	return state.GetDevices()
+}
+
+func groupsOf(state State) Groups {
+	if state == nil {
+		return nil
+	}
+	return state.GetGroups()
+}
+
+func clientsOf(state State) Clients {
+	if state == nil {
+		return nil
+	}
+	return state.GetClients()
+}
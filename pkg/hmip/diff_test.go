@@ -0,0 +1,176 @@
+package hmip
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+// changedFixtureState parses the fixture after changing its wire format.
+func changedFixtureState(t *testing.T, change func(wire map[string]any)) State {
+	t.Helper()
+	var wire map[string]any
+	if err := json.Unmarshal(readFixture(t, "state.json"), &wire); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	change(wire)
+	data, err := json.Marshal(wire)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	state, err := ParseState(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return state
+}
+
+func entityOf(wire map[string]any, kind, id string) map[string]any {
+	return wire[kind].(map[string]any)[id].(map[string]any)
+}
+
+func channelOf(wire map[string]any, deviceID, index string) map[string]any {
+	return entityOf(wire, "devices", deviceID)["functionalChannels"].(map[string]any)[index].(map[string]any)
+}
+
+func TestDiffIgnoresTimestamps(t *testing.T) {
+	old := parseFixtureState(t)
+	new := changedFixtureState(t, func(wire map[string]any) {
+		entityOf(wire, "devices", coffeeMachineID)["lastStatusUpdate"] = 1760790060000
+		entityOf(wire, "groups", "g-switch")["lastStatusUpdate"] = 1760790060000
+		entityOf(wire, "clients", "c-go")["lastSeenAtTimestamp"] = 1760790060000
+	})
+
+	if diff := Diff(old, new); !diff.IsEmpty() {
+		t.Errorf("expected no differences, got\n%s", diff)
+	}
+
+	diff := DiffWithOptions(old, new, DiffOptions{})
+	if len(diff.Devices) != 1 || len(diff.Groups) != 1 || len(diff.Clients) != 1 {
+		t.Fatalf("expected the timestamps without ignored attributes, got\n%s", diff)
+	}
+	expected := FieldDiff{Path: "lastStatusUpdate", Old: float64(1760790000000), New: float64(1760790060000)}
+	if !slices.Equal(diff.Devices[0].Fields, []FieldDiff{expected}) {
+		t.Errorf("unexpected fields %v", diff.Devices[0].Fields)
+	}
+}
+
+func TestDiffRename(t *testing.T) {
+	old := parseFixtureState(t)
+	new := changedFixtureState(t, func(wire map[string]any) {
+		entityOf(wire, "devices", coffeeMachineID)["label"] = "Espresso"
+		channelOf(wire, "3014F711A0000E9BE9A3C0DE", "2")["label"] = "Links"
+	})
+
+	diff := Diff(old, new)
+
+	if len(diff.Devices) != 1 {
+		t.Fatalf("expected one changed device, got\n%s", diff)
+	}
+	device := diff.Devices[0]
+	if device.Change != CHANGE_TYPE_CHANGED || !device.Renamed || device.OldName != "Kaffeemaschine" || device.Name != "Espresso" {
+		t.Errorf("unexpected device diff %+v", device)
+	}
+	if len(diff.Channels) != 1 {
+		t.Fatalf("expected one changed channel, got\n%s", diff)
+	}
+	channel := diff.Channels[0]
+	if channel.Index != 2 || !channel.Renamed || channel.OldLabel != "Oben" || channel.Label != "Links" {
+		t.Errorf("unexpected channel diff %+v", channel)
+	}
+	expected := "~ device \"Espresso\" (" + coffeeMachineID + ") renamed from \"Kaffeemaschine\"\n" +
+		"    label: \"Kaffeemaschine\" -> \"Espresso\"\n" +
+		"~ channel 2 \"Links\" of device \"Schalter Flur\" (3014F711A0000E9BE9A3C0DE) renamed from \"Oben\"\n" +
+		"    label: \"Oben\" -> \"Links\"\n"
+	if diff.String() != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, diff)
+	}
+}
+
+func TestDiffChannelChanges(t *testing.T) {
+	old := parseFixtureState(t)
+	new := changedFixtureState(t, func(wire map[string]any) {
+		channelOf(wire, coffeeMachineID, "1")["on"] = false
+		channelOf(wire, coffeeMachineID, "1")["currentPowerConsumption"] = 0
+		channelOf(wire, "3014F711A0000A1D89A31F06", "0")["supportedOptionalFeatures"].(map[string]any)["IOptionalFeatureLowBat"] = false
+		channels := entityOf(wire, "devices", "3014F711A0000E9BE9A3C0DE")["functionalChannels"].(map[string]any)
+		delete(channels, "3")
+	})
+
+	diff := Diff(old, new)
+
+	if len(diff.Devices) != 0 {
+		t.Errorf("expected no device changes, got %v", diff.Devices)
+	}
+	if len(diff.Channels) != 3 {
+		t.Fatalf("expected three channel changes, got\n%s", diff)
+	}
+	expected := []FieldDiff{
+		{Path: "currentPowerConsumption", Old: 1234.5, New: float64(0)},
+		{Path: "on", Old: true, New: false},
+	}
+	// Channels are listed in the order of the devices
+	if diff.Channels[0].Index != 0 || diff.Channels[0].Fields[0].Path != "supportedOptionalFeatures.IOptionalFeatureLowBat" {
+		t.Errorf("expected nested attributes by dotted path, got %+v", diff.Channels[0])
+	}
+	if diff.Channels[1].DeviceID != coffeeMachineID || !slices.Equal(diff.Channels[1].Fields, expected) {
+		t.Errorf("unexpected channel diff %+v", diff.Channels[1])
+	}
+	if diff.Channels[2].Index != 3 || diff.Channels[2].Change != CHANGE_TYPE_REMOVED {
+		t.Errorf("expected removed channel, got %+v", diff.Channels[2])
+	}
+}
+
+func TestDiffGroupMembership(t *testing.T) {
+	old := parseFixtureState(t)
+	new := changedFixtureState(t, func(wire map[string]any) {
+		// The coffee machine leaves the switching group and joins the security zone
+		channelOf(wire, coffeeMachineID, "1")["groups"] = []string{"g-meta-kitchen", "g-security-internal"}
+		entityOf(wire, "groups", "g-switch")["channels"] = []any{}
+		security := entityOf(wire, "groups", "g-security-internal")
+		security["channels"] = append(security["channels"].([]any), map[string]any{"deviceId": coffeeMachineID, "channelIndex": 1})
+	})
+
+	diff := Diff(old, new)
+
+	if len(diff.Devices) != 1 {
+		t.Fatalf("expected one changed device, got\n%s", diff)
+	}
+	device := diff.Devices[0]
+	if !slices.Equal(device.JoinedGroups, []string{"g-security-internal"}) || !slices.Equal(device.LeftGroups, []string{"g-switch"}) {
+		t.Errorf("unexpected group changes %+v", device)
+	}
+	if len(device.Fields) != 0 || device.Renamed {
+		t.Errorf("expected only group changes, got %+v", device)
+	}
+	if len(diff.Groups) != 2 || len(diff.Channels) != 1 {
+		t.Errorf("expected the changed groups and channel, got\n%s", diff)
+	}
+}
+
+func TestDiffAddedAndRemoved(t *testing.T) {
+	old := parseFixtureState(t)
+	new := changedFixtureState(t, func(wire map[string]any) {
+		delete(wire["devices"].(map[string]any), balconySensorID)
+		delete(wire["clients"].(map[string]any), "c-app")
+		wire["groups"].(map[string]any)["g-new"] = map[string]any{"id": "g-new", "type": "SWITCHING", "label": "Neu", "channels": []any{}}
+	})
+
+	diff := Diff(old, new)
+
+	if len(diff.Devices) != 1 || diff.Devices[0].Change != CHANGE_TYPE_REMOVED || diff.Devices[0].Name != "Balkon" {
+		t.Errorf("expected removed device, got %v", diff.Devices)
+	}
+	if len(diff.Clients) != 1 || diff.Clients[0].Change != CHANGE_TYPE_REMOVED {
+		t.Errorf("expected removed client, got %v", diff.Clients)
+	}
+	if len(diff.Groups) != 1 || diff.Groups[0].Change != CHANGE_TYPE_ADDED || diff.Groups[0].ID != "g-new" {
+		t.Errorf("expected added group, got %v", diff.Groups)
+	}
+	if len(diff.Channels) != 0 {
+		t.Errorf("expected no channels of removed devices, got %v", diff.Channels)
+	}
+	if len(Diff(nil, old).Devices) != len(old.GetDevices()) {
+		t.Error("expected all devices added to a nil state")
+	}
+}
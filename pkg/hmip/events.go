@@ -1,20 +1,48 @@
 package hmip
 
-import "encoding/json"
+import (
+	"bytes"
+	"errors"
+	"fmt"
+)
 
 type event struct {
-	Type string `json:"pushEventType"`
+	raw
+	Type     string `json:"pushEventType"`
+	warnings []DecodeWarning
 }
 
 func (e event) GetType() string {
 	return e.Type
 }
 
+func (e event) GetDecodeWarnings() []DecodeWarning {
+	return e.warnings
+}
+
+// skippedEntityError is returned for events whose nested entity was skipped in lenient mode,
+// so the event is skipped as well instead of being handled without the entity.
+func skippedEntityError(path string) error {
+	return errors.New(fmt.Sprintf("Error on decoding event (%s was skipped)", path))
+}
+
 // ======================================================
 
 type deviceChangedEvent struct {
-	event
-	Device device `json:"device"`
+	*event
+	Device Device `json:"-"`
+}
+
+func (dce *deviceChangedEvent) decode(d *decoder, path string, value []byte) error {
+	device, err := peekMember(value, "device")
+	if err != nil || device == nil {
+		return err
+	}
+	dce.Device, err = d.decodeDevice(path+".device", device)
+	if err == nil && dce.Device == nil {
+		return skippedEntityError(path + ".device")
+	}
+	return err
 }
 
 func (dce deviceChangedEvent) GetDevice() Device {
@@ -23,7 +51,7 @@ func (dce deviceChangedEvent) GetDevice() Device {
 
 func (dce deviceChangedEvent) GetFunctionalChannels(deviceType, channelType string) FunctionalChannels {
 	var channels FunctionalChannels
-	if dce.Device.GetType() == deviceType {
+	if dce.Device != nil && dce.Device.GetType() == deviceType {
 		for _, channel := range dce.Device.GetFunctionalChannels() {
 			if channel.GetType() == channelType {
 				channels = append(channels, channel)
@@ -36,8 +64,20 @@ func (dce deviceChangedEvent) GetFunctionalChannels(deviceType, channelType stri
 // ======================================================
 
 type groupChangedEvent struct {
-	event
-	Group group `json:"group"`
+	*event
+	Group Group `json:"-"`
+}
+
+func (gce *groupChangedEvent) decode(d *decoder, path string, value []byte) error {
+	group, err := peekMember(value, "group")
+	if err != nil || group == nil {
+		return err
+	}
+	gce.Group, err = d.decodeGroup(path+".group", group)
+	if err == nil && gce.Group == nil {
+		return skippedEntityError(path + ".group")
+	}
+	return err
 }
 
 func (gce groupChangedEvent) GetGroup() Group {
@@ -46,41 +86,33 @@ func (gce groupChangedEvent) GetGroup() Group {
 
 // ======================================================
 
-func (e *Events) UnmarshalJSON(value []byte) error {
-	var eventValues map[string]json.RawMessage
-	err := json.Unmarshal(value, &eventValues)
+type homeChangedEvent struct {
+	*event
+	Home *home `json:"-"`
+}
+
+func (hce *homeChangedEvent) decode(d *decoder, path string, value []byte) error {
+	home, err := peekMember(value, "home")
 	if err != nil {
 		return err
 	}
-	events := make(Events, 0, len(eventValues))
-	for _, eventValue := range eventValues {
-		var event event
-		err = json.Unmarshal(eventValue, &event)
-		if err != nil {
-			return err
-		}
-		switch event.Type {
-		case EVENT_TYPE_DEVICE_CHANGED:
-			specialEvent := deviceChangedEvent{
-				event: event,
-			}
-			err = json.Unmarshal(eventValue, &specialEvent)
-			if err != nil {
-				return err
-			}
-			events = append(events, &specialEvent)
-		case EVENT_TYPE_GROUP_CHANGED:
-			specialEvent := groupChangedEvent{
-				event: event,
-			}
-			err = json.Unmarshal(eventValue, &specialEvent)
-			if err != nil {
-				return err
-			}
-			events = append(events, &specialEvent)
-		default:
-			events = append(events, &event)
-		}
+	hce.Home, err = d.decodeHome(path+".home", home)
+	return err
+}
+
+func (hce homeChangedEvent) GetHome() Home {
+	if hce.Home == nil {
+		return nil
+	}
+	return hce.Home
+}
+
+// ======================================================
+
+func (e *Events) UnmarshalJSON(value []byte) error {
+	events, err := newDecoder(DecodeOptions{}).decodeEvents("events", bytes.Clone(value))
+	if err != nil {
+		return err
 	}
 	*e = events
 	return nil
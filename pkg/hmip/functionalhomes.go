@@ -0,0 +1,228 @@
+package hmip
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"time"
+)
+
+const absenceEndTimeLayout = "2006_01_02 15:04"
+
+type functionalHome struct {
+	Type             string   `json:"solution"`
+	Active           bool     `json:"active"`
+	FunctionalGroups []string `json:"functionalGroups"`
+}
+
+func (fh functionalHome) GetType() string {
+	return fh.Type
+}
+
+func (fh functionalHome) IsActive() bool {
+	return fh.Active
+}
+
+func (fh functionalHome) GetFunctionalGroups() []string {
+	return fh.FunctionalGroups
+}
+
+// ======================================================
+
+type indoorClimateHome struct {
+	functionalHome
+	EcoTemperature          float64 `json:"ecoTemperature"`
+	EcoDuration             string  `json:"ecoDuration"`
+	AbsenceType             string  `json:"absenceType"`
+	AbsenceEndTime          string  `json:"absenceEndTime"`
+	OptimumStartStopEnabled bool    `json:"optimumStartStopEnabled"`
+	// timeZone is the time zone of the home the absence end time is given in
+	timeZone *time.Location
+}
+
+func (ich indoorClimateHome) GetEcoTemperature() float64 {
+	return ich.EcoTemperature
+}
+
+func (ich indoorClimateHome) GetEcoDuration() string {
+	return ich.EcoDuration
+}
+
+func (ich indoorClimateHome) GetAbsenceType() string {
+	return ich.AbsenceType
+}
+
+func (ich indoorClimateHome) GetAbsenceEndTime() time.Time {
+	timeZone := ich.timeZone
+	if timeZone == nil {
+		timeZone = time.Local
+	}
+	endTime, err := time.ParseInLocation(absenceEndTimeLayout, ich.AbsenceEndTime, timeZone)
+	if err != nil {
+		return time.Time{}
+	}
+	return endTime
+}
+
+func (ich indoorClimateHome) IsOptimumStartStopEnabled() bool {
+	return ich.OptimumStartStopEnabled
+}
+
+func (ich *indoorClimateHome) setTimeZone(timeZone *time.Location) {
+	ich.timeZone = timeZone
+}
+
+// ======================================================
+
+type securityAndAlarmHome struct {
+	functionalHome
+	ActivationInProgress                bool                `json:"activationInProgress"`
+	AlarmActive                         bool                `json:"alarmActive"`
+	AlarmEventTimestamp                 *HomematicTimestamp `json:"alarmEventTimestamp"`
+	AlarmEventDeviceID                  string              `json:"alarmEventDeviceId"`
+	IntrusionAlertThroughSmokeDetectors bool                `json:"intrusionAlertThroughSmokeDetectors"`
+	ZoneActivationDelay                 float64             `json:"zoneActivationDelay"`
+	SecurityZoneActivationMode          string              `json:"securityZoneActivationMode"`
+	SecurityZones                       map[string]string   `json:"securityZones"`
+}
+
+func (sah securityAndAlarmHome) IsActivationInProgress() bool {
+	return sah.ActivationInProgress
+}
+
+func (sah securityAndAlarmHome) IsAlarmActive() bool {
+	return sah.AlarmActive
+}
+
+func (sah securityAndAlarmHome) GetAlarmEventTime() time.Time {
+	if sah.AlarmEventTimestamp == nil {
+		return time.Time{}
+	}
+	return sah.AlarmEventTimestamp.Time
+}
+
+func (sah securityAndAlarmHome) GetAlarmEventDeviceID() string {
+	return sah.AlarmEventDeviceID
+}
+
+func (sah securityAndAlarmHome) HasIntrusionAlertThroughSmokeDetectors() bool {
+	return sah.IntrusionAlertThroughSmokeDetectors
+}
+
+func (sah securityAndAlarmHome) GetZoneActivationDelay() float64 {
+	return sah.ZoneActivationDelay
+}
+
+func (sah securityAndAlarmHome) GetSecurityZoneActivationMode() string {
+	return sah.SecurityZoneActivationMode
+}
+
+func (sah securityAndAlarmHome) GetSecurityZones() map[string]string {
+	return sah.SecurityZones
+}
+
+// ======================================================
+
+type lightAndShadowHome struct {
+	functionalHome
+	ExtendedLinkedShutterGroups   []string `json:"extendedLinkedShutterGroups"`
+	ExtendedLinkedSwitchingGroups []string `json:"extendedLinkedSwitchingGroups"`
+	ShutterProfileGroups          []string `json:"shutterProfileGroups"`
+	SwitchingProfileGroups        []string `json:"switchingProfileGroups"`
+}
+
+func (lsh lightAndShadowHome) GetExtendedLinkedShutterGroups() []string {
+	return lsh.ExtendedLinkedShutterGroups
+}
+
+func (lsh lightAndShadowHome) GetExtendedLinkedSwitchingGroups() []string {
+	return lsh.ExtendedLinkedSwitchingGroups
+}
+
+func (lsh lightAndShadowHome) GetShutterProfileGroups() []string {
+	return lsh.ShutterProfileGroups
+}
+
+func (lsh lightAndShadowHome) GetSwitchingProfileGroups() []string {
+	return lsh.SwitchingProfileGroups
+}
+
+// ======================================================
+
+// weatherAndEnvironmentHome and energyHome contain only the common functional home data
+type weatherAndEnvironmentHome struct {
+	functionalHome
+}
+
+func (weh weatherAndEnvironmentHome) isWeatherAndEnvironmentHome() {}
+
+// ======================================================
+
+type energyHome struct {
+	functionalHome
+}
+
+func (eh energyHome) isEnergyHome() {}
+
+// ======================================================
+
+// UnmarshalJSON decodes the functional homes sorted by their solution.
+func (fh *FunctionalHomes) UnmarshalJSON(value []byte) error {
+	var functionalHomeValues map[string]json.RawMessage
+	err := json.Unmarshal(value, &functionalHomeValues)
+	if err != nil {
+		return err
+	}
+	functionalHomes := make(FunctionalHomes, 0, len(functionalHomeValues))
+	for _, functionalHomeValue := range functionalHomeValues {
+		var functionalHome functionalHome
+		err = json.Unmarshal(functionalHomeValue, &functionalHome)
+		if err != nil {
+			return err
+		}
+		switch functionalHome.Type {
+		case FUNCTIONAL_HOME_TYPE_INDOOR_CLIMATE:
+			specialFunctionalHome := indoorClimateHome{
+				functionalHome: functionalHome,
+			}
+			err = json.Unmarshal(functionalHomeValue, &specialFunctionalHome)
+			if err != nil {
+				return err
+			}
+			functionalHomes = append(functionalHomes, &specialFunctionalHome)
+		case FUNCTIONAL_HOME_TYPE_SECURITY_AND_ALARM:
+			specialFunctionalHome := securityAndAlarmHome{
+				functionalHome: functionalHome,
+			}
+			err = json.Unmarshal(functionalHomeValue, &specialFunctionalHome)
+			if err != nil {
+				return err
+			}
+			functionalHomes = append(functionalHomes, &specialFunctionalHome)
+		case FUNCTIONAL_HOME_TYPE_LIGHT_AND_SHADOW:
+			specialFunctionalHome := lightAndShadowHome{
+				functionalHome: functionalHome,
+			}
+			err = json.Unmarshal(functionalHomeValue, &specialFunctionalHome)
+			if err != nil {
+				return err
+			}
+			functionalHomes = append(functionalHomes, &specialFunctionalHome)
+		case FUNCTIONAL_HOME_TYPE_WEATHER_AND_ENVIRONMENT:
+			functionalHomes = append(functionalHomes, &weatherAndEnvironmentHome{
+				functionalHome: functionalHome,
+			})
+		case FUNCTIONAL_HOME_TYPE_ENERGY:
+			functionalHomes = append(functionalHomes, &energyHome{
+				functionalHome: functionalHome,
+			})
+		default:
+			functionalHomes = append(functionalHomes, &functionalHome)
+		}
+	}
+	slices.SortFunc(functionalHomes, func(a, b FunctionalHome) int {
+		return strings.Compare(a.GetType(), b.GetType())
+	})
+	*fh = functionalHomes
+	return nil
+}
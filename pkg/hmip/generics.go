@@ -0,0 +1,67 @@
+package hmip
+
+// TypedChannel is a functional channel implementing T together with the device it belongs to.
+type TypedChannel[T any] struct {
+	Device  Device
+	Channel T
+}
+
+// DeviceWith is a device together with all of its functional channels implementing T.
+type DeviceWith[T any] struct {
+	Device   Device
+	Channels []T
+}
+
+// ChannelsOf returns all functional channels of the state implementing T,
+// which may be a special channel interface like SwitchMeasuringChannel or
+// a capability like ClimateMeasuring.
+func ChannelsOf[T any](state State) []TypedChannel[T] {
+	var channels []TypedChannel[T]
+	for _, device := range state.GetDevices() {
+		for _, channel := range ChannelsOfDevice[T](device) {
+			channels = append(channels, TypedChannel[T]{
+				Device:  device,
+				Channel: channel,
+			})
+		}
+	}
+	return channels
+}
+
+// ChannelsOfDevice returns all functional channels of the device implementing T.
+func ChannelsOfDevice[T any](device Device) []T {
+	var channels []T
+	for _, channel := range device.GetFunctionalChannels() {
+		if typedChannel, ok := channel.(T); ok {
+			channels = append(channels, typedChannel)
+		}
+	}
+	return channels
+}
+
+// FirstChannel returns the functional channel of the device with the
+// lowest index implementing T and false, if there is none.
+func FirstChannel[T any](device Device) (T, bool) {
+	for _, channel := range device.GetFunctionalChannels() {
+		if typedChannel, ok := channel.(T); ok {
+			return typedChannel, true
+		}
+	}
+	var none T
+	return none, false
+}
+
+// DevicesWith returns all devices of the state having at least one
+// functional channel implementing T together with these channels.
+func DevicesWith[T any](state State) []DeviceWith[T] {
+	var devices []DeviceWith[T]
+	for _, device := range state.GetDevices() {
+		if channels := ChannelsOfDevice[T](device); len(channels) > 0 {
+			devices = append(devices, DeviceWith[T]{
+				Device:   device,
+				Channels: channels,
+			})
+		}
+	}
+	return devices
+}
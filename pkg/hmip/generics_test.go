@@ -0,0 +1,160 @@
+package hmip
+
+import (
+	"slices"
+	"testing"
+)
+
+const notificationLightID = "3014F711A0000E9BE9A3C0DE"
+
+func TestChannelsOf(t *testing.T) {
+	state := parseFixtureState(t)
+	tests := []struct {
+		name      string
+		deviceIDs func(State) []string
+		expected  []string
+	}{
+		{"all channels", deviceIDsOf[FunctionalChannel], nil},
+		{"special channel", deviceIDsOf[SwitchMeasuringChannel], []string{coffeeMachineID}},
+		{"exact special channel", deviceIDsOf[ClimateSensorChannel], []string{balconySensorID}},
+		{"climate measuring", deviceIDsOf[ClimateMeasuring], []string{"3014F711A00003D8A99B7A11", balconySensorID}},
+		{"switchable", deviceIDsOf[Switchable], []string{coffeeMachineID, "3014F711A0000E1BE9A3C0D7", notificationLightID, notificationLightID, notificationLightID}},
+		{"openable", deviceIDsOf[Openable], []string{"3014F711A00000DA4990B218", "3014F711A00001D569A5E4F9"}},
+		{"no channel", deviceIDsOf[interface{ GetUnknownValue() int }], []string{}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			deviceIDs := test.deviceIDs(state)
+			if test.expected == nil {
+				if len(deviceIDs) != 31 {
+					t.Errorf("expected all 31 channels, got %d", len(deviceIDs))
+				}
+				return
+			}
+			slices.Sort(deviceIDs)
+			if !slices.Equal(deviceIDs, test.expected) {
+				t.Errorf("expected channels of %v, got %v", test.expected, deviceIDs)
+			}
+		})
+	}
+}
+
+// deviceIDsOf returns the IDs of the devices of all channels returned by ChannelsOf,
+// checking that each channel belongs to its device.
+func deviceIDsOf[T any](state State) []string {
+	deviceIDs := []string{}
+	for _, typed := range ChannelsOf[T](state) {
+		channel := any(typed.Channel).(FunctionalChannel)
+		if typed.Device.GetChannel(channel.GetIndex()) != channel {
+			return []string{"channel not of device " + typed.Device.GetID()}
+		}
+		deviceIDs = append(deviceIDs, typed.Device.GetID())
+	}
+	return deviceIDs
+}
+
+func TestChannelsOfDevice(t *testing.T) {
+	state := parseFixtureState(t)
+	notificationLight := state.GetDeviceByID(notificationLightID)
+	coffeeMachine := state.GetDeviceByID(coffeeMachineID)
+	tests := []struct {
+		name     string
+		indexes  []int
+		expected []int
+	}{
+		{"all channels", channelIndexes(ChannelsOfDevice[FunctionalChannel](notificationLight)), []int{0, 1, 2, 3}},
+		{"switchable", channelIndexes(ChannelsOfDevice[Switchable](notificationLight)), []int{1, 2, 3}},
+		{"dimmable", channelIndexes(ChannelsOfDevice[Dimmable](notificationLight)), []int{2, 3}},
+		{"special channel", channelIndexes(ChannelsOfDevice[NotificationLightChannel](notificationLight)), []int{2, 3}},
+		{"power measuring", channelIndexes(ChannelsOfDevice[PowerConsumptionMeasuring](coffeeMachine)), []int{1}},
+		{"not implemented", channelIndexes(ChannelsOfDevice[ClimateMeasuring](coffeeMachine)), nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if !slices.Equal(test.indexes, test.expected) {
+				t.Errorf("expected channels %v, got %v", test.expected, test.indexes)
+			}
+		})
+	}
+}
+
+func channelIndexes[T any](channels []T) []int {
+	var indexes []int
+	for _, channel := range channels {
+		indexes = append(indexes, any(channel).(FunctionalChannel).GetIndex())
+	}
+	return indexes
+}
+
+func TestFirstChannel(t *testing.T) {
+	state := parseFixtureState(t)
+	notificationLight := state.GetDeviceByID(notificationLightID)
+	tests := []struct {
+		name     string
+		index    func() (int, bool)
+		expected int
+		found    bool
+	}{
+		{"lowest index", firstChannelIndex[Switchable](notificationLight), 1, true},
+		{"special channel", firstChannelIndex[NotificationLightChannel](notificationLight), 2, true},
+		{"base channel", firstChannelIndex[BaseDeviceChannel](notificationLight), 0, true},
+		{"climate measuring", firstChannelIndex[ClimateMeasuring](state.GetDeviceByID(balconySensorID)), 1, true},
+		{"none", firstChannelIndex[DimmerChannel](notificationLight), 0, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			index, found := test.index()
+			if index != test.expected || found != test.found {
+				t.Errorf("expected index %d (%t), got %d (%t)", test.expected, test.found, index, found)
+			}
+		})
+	}
+
+	if channel, ok := FirstChannel[ClimateMeasuring](state.GetDeviceByID(coffeeMachineID)); ok || channel != nil {
+		t.Errorf("expected the zero value without channel, got %v", channel)
+	}
+}
+
+func firstChannelIndex[T any](device Device) func() (int, bool) {
+	return func() (int, bool) {
+		channel, ok := FirstChannel[T](device)
+		if !ok {
+			return 0, false
+		}
+		return any(channel).(FunctionalChannel).GetIndex(), true
+	}
+}
+
+func TestDevicesWith(t *testing.T) {
+	state := parseFixtureState(t)
+	tests := []struct {
+		name     string
+		devices  map[string]int
+		expected map[string]int
+	}{
+		{"switchable", channelCounts(DevicesWith[Switchable](state)), map[string]int{"Kaffeemaschine": 1, "Licht Flur": 1, "Schalter Flur": 3}},
+		{"climate measuring", channelCounts(DevicesWith[ClimateMeasuring](state)), map[string]int{"Balkon": 1, "Wandthermostat Küche": 1}},
+		{"special channel", channelCounts(DevicesWith[HeatingThermostatChannel](state)), map[string]int{"Heizung Küche": 1}},
+		{"no device", channelCounts(DevicesWith[interface{ GetUnknownValue() int }](state)), map[string]int{}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if len(test.devices) != len(test.expected) {
+				t.Fatalf("expected devices %v, got %v", test.expected, test.devices)
+			}
+			for name, count := range test.expected {
+				if test.devices[name] != count {
+					t.Errorf("expected %d channels of %s, got %d", count, name, test.devices[name])
+				}
+			}
+		})
+	}
+}
+
+func channelCounts[T any](devices []DeviceWith[T]) map[string]int {
+	counts := make(map[string]int)
+	for _, device := range devices {
+		counts[device.Device.GetName()] = len(device.Channels)
+	}
+	return counts
+}
@@ -1,53 +1,89 @@
 package hmip
 
-import "encoding/json"
+import "bytes"
 
 type group struct {
+	raw
 	stateful
 	named
 	typed
+	Channels []channelReference `json:"channels"`
+	state    *state
+}
+
+type channelReference struct {
+	DeviceID     string `json:"deviceId"`
+	ChannelIndex int    `json:"channelIndex"`
+}
+
+func (g group) GetMembers() GroupMembers {
+	if g.state == nil {
+		return nil
+	}
+	var members GroupMembers
+	for _, reference := range g.Channels {
+		device := g.state.GetDeviceByID(reference.DeviceID)
+		if device == nil {
+			continue
+		}
+		channel := device.GetChannel(reference.ChannelIndex)
+		if channel == nil {
+			continue
+		}
+		members = append(members, groupMember{
+			device:  device,
+			channel: channel,
+		})
+	}
+	return members
+}
+
+func (g *group) setState(state *state) {
+	g.state = state
+}
+
+func (g *group) groupBase() *group {
+	return g
+}
+
+// ======================================================
+
+type groupMember struct {
+	device  Device
+	channel FunctionalChannel
+}
+
+func (gm groupMember) GetDevice() Device {
+	return gm.device
+}
+
+func (gm groupMember) GetChannel() FunctionalChannel {
+	return gm.channel
 }
 
 // ======================================================
 
 type metaGroup struct {
-	group
-	Icon string `json:"groupIcon"`
+	*group
+	Icon     string   `json:"groupIcon"`
+	GroupIDs []string `json:"groups"`
 }
 
 func (mg metaGroup) GetIcon() string {
 	return mg.Icon
 }
 
+func (mg metaGroup) GetGroupIDs() []string {
+	return mg.GroupIDs
+}
+
 // ======================================================
 
 func (g *Groups) UnmarshalJSON(value []byte) error {
-	var groupValues map[string]json.RawMessage
-	err := json.Unmarshal(value, &groupValues)
+	groups, err := newDecoder(DecodeOptions{}).decodeGroups("groups", bytes.Clone(value))
 	if err != nil {
 		return err
 	}
-	groups := make(Groups, 0, len(groupValues))
-	for _, groupValue := range groupValues {
-		var group group
-		err = json.Unmarshal(groupValue, &group)
-		if err != nil {
-			return err
-		}
-		switch group.Type {
-		case GROUP_TYPE_META:
-			specialGroup := metaGroup{
-				group: group,
-			}
-			err = json.Unmarshal(groupValue, &specialGroup)
-			if err != nil {
-				return err
-			}
-			groups = append(groups, &specialGroup)
-		default:
-			groups = append(groups, &group)
-		}
-	}
 	*g = groups
 	return nil
 }
@@ -0,0 +1,234 @@
+package hmip
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+type home struct {
+	raw
+	ID                  string          `json:"id"`
+	CurrentAPVersion    string          `json:"currentAPVersion"`
+	AvailableAPVersion  string          `json:"availableAPVersion"`
+	Connected           bool            `json:"connected"`
+	DutyCycle           float64         `json:"dutyCycle"`
+	CarrierSense        float64         `json:"carrierSense"`
+	TimeZoneID          string          `json:"timeZoneId"`
+	PINAssigned         bool            `json:"pinAssigned"`
+	UpdateState         string          `json:"updateState"`
+	PowerMeterUnitPrice float64         `json:"powerMeterUnitPrice"`
+	PowerMeterCurrency  string          `json:"powerMeterCurrency"`
+	Weather             *weather        `json:"weather"`
+	Location            *location       `json:"location"`
+	FunctionalHomes     FunctionalHomes `json:"functionalHomes"`
+}
+
+func (h *home) UnmarshalJSON(value []byte) error {
+	return h.decode(bytes.Clone(value))
+}
+
+// decode decodes the home keeping the value as raw JSON without copying it.
+func (h *home) decode(value []byte) error {
+	type plainHome home
+	h.setRaw(value)
+	err := json.Unmarshal(value, (*plainHome)(h))
+	h.bindTimeZone()
+	return err
+}
+
+// bindTimeZone passes the time zone of the home to the functional homes containing local times.
+// The local time zone is used if the time zone of the home is unknown.
+func (h *home) bindTimeZone() {
+	timeZone, err := time.LoadLocation(h.TimeZoneID)
+	if err != nil || h.TimeZoneID == "" {
+		timeZone = time.Local
+	}
+	for _, functionalHome := range h.FunctionalHomes {
+		if bound, ok := functionalHome.(interface{ setTimeZone(*time.Location) }); ok {
+			bound.setTimeZone(timeZone)
+		}
+	}
+}
+
+func (h home) GetID() string {
+	return h.ID
+}
+
+func (h home) GetCurrentAPVersion() string {
+	return h.CurrentAPVersion
+}
+
+func (h home) GetAvailableAPVersion() string {
+	return h.AvailableAPVersion
+}
+
+func (h home) IsConnected() bool {
+	return h.Connected
+}
+
+func (h home) GetDutyCycle() float64 {
+	return h.DutyCycle
+}
+
+func (h home) GetCarrierSense() float64 {
+	return h.CarrierSense
+}
+
+func (h home) GetTimeZoneID() string {
+	return h.TimeZoneID
+}
+
+func (h home) IsPINAssigned() bool {
+	return h.PINAssigned
+}
+
+func (h home) GetUpdateState() string {
+	return h.UpdateState
+}
+
+func (h home) GetPowerMeterUnitPrice() float64 {
+	return h.PowerMeterUnitPrice
+}
+
+func (h home) GetPowerMeterCurrency() string {
+	return h.PowerMeterCurrency
+}
+
+func (h home) GetWeather() Weather {
+	if h.Weather == nil {
+		return nil
+	}
+	return h.Weather
+}
+
+func (h home) GetLocation() Location {
+	if h.Location == nil {
+		return nil
+	}
+	return h.Location
+}
+
+func (h home) GetFunctionalHomes() FunctionalHomes {
+	return h.FunctionalHomes
+}
+
+func (h home) GetFunctionalHome(functionalHomeType string) FunctionalHome {
+	for _, functionalHome := range h.FunctionalHomes {
+		if functionalHome.GetType() == functionalHomeType {
+			return functionalHome
+		}
+	}
+	return nil
+}
+
+// ======================================================
+
+type weather struct {
+	Temperature      float64 `json:"temperature"`
+	MinTemperature   float64 `json:"minTemperature"`
+	MaxTemperature   float64 `json:"maxTemperature"`
+	Humidity         int     `json:"humidity"`
+	VapourAmount     float64 `json:"vaporAmount"`
+	WindSpeed        float64 `json:"windSpeed"`
+	WindDirection    int     `json:"windDirection"`
+	WeatherCondition string  `json:"weatherCondition"`
+	WeatherDayTime   string  `json:"weatherDayTime"`
+}
+
+func (w weather) GetTemperature() float64 {
+	return w.Temperature
+}
+
+func (w weather) GetMinTemperature() float64 {
+	return w.MinTemperature
+}
+
+func (w weather) GetMaxTemperature() float64 {
+	return w.MaxTemperature
+}
+
+func (w weather) GetHumidity() int {
+	return w.Humidity
+}
+
+func (w weather) GetVapourAmount() float64 {
+	return w.VapourAmount
+}
+
+func (w weather) GetWindSpeed() float64 {
+	return w.WindSpeed
+}
+
+func (w weather) GetWindDirection() int {
+	return w.WindDirection
+}
+
+func (w weather) GetWeatherCondition() string {
+	return w.WeatherCondition
+}
+
+func (w weather) GetWeatherDayTime() string {
+	return w.WeatherDayTime
+}
+
+// ======================================================
+
+// location contains the coordinates parsed from the strings sent by the cloud
+type location struct {
+	City      string
+	Latitude  float64
+	Longitude float64
+}
+
+// UnmarshalJSON parses the coordinates, an unparsable coordinate is reported
+// as type error of the attribute, so the rest of the home is still decoded.
+func (l *location) UnmarshalJSON(value []byte) error {
+	var plain struct {
+		City      string `json:"city"`
+		Latitude  string `json:"latitude"`
+		Longitude string `json:"longitude"`
+	}
+	err := json.Unmarshal(value, &plain)
+	if err != nil {
+		return err
+	}
+	l.City = plain.City
+	l.Latitude, err = parseCoordinate("latitude", plain.Latitude)
+	var longitudeErr error
+	l.Longitude, longitudeErr = parseCoordinate("longitude", plain.Longitude)
+	if err == nil {
+		err = longitudeErr
+	}
+	return err
+}
+
+func (l location) GetCity() string {
+	return l.City
+}
+
+func (l location) GetLatitude() float64 {
+	return l.Latitude
+}
+
+func (l location) GetLongitude() float64 {
+	return l.Longitude
+}
+
+// parseCoordinate parses a coordinate, a missing coordinate is zero.
+func parseCoordinate(name, value string) (float64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	coordinate, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, &json.UnmarshalTypeError{
+			Value: "string " + strconv.Quote(value),
+			Type:  reflect.TypeOf(coordinate),
+			Field: name,
+		}
+	}
+	return coordinate, nil
+}
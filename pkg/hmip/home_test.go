@@ -0,0 +1,77 @@
+package hmip
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestLocationCoordinates(t *testing.T) {
+	state, err := ParseState([]byte(`{"home":{"id":"home","location":{"city":"Berlin","latitude":"52.520008","longitude":"13.404954"}}}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	location := state.GetHome().GetLocation()
+	if location.GetCity() != "Berlin" || location.GetLatitude() != 52.520008 || location.GetLongitude() != 13.404954 {
+		t.Errorf("unexpected location %s %f %f", location.GetCity(), location.GetLatitude(), location.GetLongitude())
+	}
+}
+
+func TestLocationInvalidCoordinates(t *testing.T) {
+	data := []byte(`{"home":{"id":"home","location":{"city":"Berlin","latitude":"52,52","longitude":"13.404954"},"timeZoneId":"Europe/Berlin"}}`)
+
+	_, err := ParseState(data)
+	if err == nil {
+		t.Fatal("expected error for invalid latitude")
+	}
+
+	state, err := ParseStateWithOptions(data, DecodeOptions{Lenient: true})
+	if err != nil {
+		t.Fatalf("unexpected error in lenient mode: %v", err)
+	}
+	warnings := state.GetDecodeWarnings()
+	if len(warnings) != 1 || !warnings[0].Partial || warnings[0].Path != "home" {
+		t.Fatalf("expected one partial warning for the home, got %v", warnings)
+	}
+	home := state.GetHome()
+	if home.GetTimeZoneID() != "Europe/Berlin" || home.GetLocation().GetLongitude() != 13.404954 {
+		t.Error("expected the rest of the home to be decoded")
+	}
+}
+
+func TestFunctionalHomes(t *testing.T) {
+	state, err := ParseState([]byte(`{"home":{"id":"home","timeZoneId":"America/New_York","functionalHomes":{
+		"WEATHER_AND_ENVIRONMENT":{"solution":"WEATHER_AND_ENVIRONMENT","active":true},
+		"INDOOR_CLIMATE":{"solution":"INDOOR_CLIMATE","active":true,"absenceType":"PERIOD","absenceEndTime":"2026_12_24 18:30"},
+		"ENERGY":{"solution":"ENERGY","active":false},
+		"LIGHT_AND_SHADOW":{"solution":"LIGHT_AND_SHADOW","active":true}}}}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	functionalHomes := state.GetHome().GetFunctionalHomes()
+
+	var solutions []string
+	for _, functionalHome := range functionalHomes {
+		solutions = append(solutions, functionalHome.GetType())
+	}
+	expected := []string{FUNCTIONAL_HOME_TYPE_ENERGY, FUNCTIONAL_HOME_TYPE_INDOOR_CLIMATE, FUNCTIONAL_HOME_TYPE_LIGHT_AND_SHADOW, FUNCTIONAL_HOME_TYPE_WEATHER_AND_ENVIRONMENT}
+	if !slices.Equal(solutions, expected) {
+		t.Errorf("expected functional homes sorted by solution %v, got %v", expected, solutions)
+	}
+	if _, ok := functionalHomes[0].(EnergyHome); !ok {
+		t.Error("expected EnergyHome")
+	}
+	if _, ok := functionalHomes[3].(WeatherAndEnvironmentHome); !ok {
+		t.Error("expected WeatherAndEnvironmentHome")
+	}
+	if _, ok := functionalHomes[3].(EnergyHome); ok {
+		t.Error("unexpected EnergyHome")
+	}
+
+	indoorClimate := state.GetHome().GetFunctionalHome(FUNCTIONAL_HOME_TYPE_INDOOR_CLIMATE).(IndoorClimateHome)
+	newYork, _ := time.LoadLocation("America/New_York")
+	expectedEndTime := time.Date(2026, 12, 24, 18, 30, 0, 0, newYork)
+	if endTime := indoorClimate.GetAbsenceEndTime(); !endTime.Equal(expectedEndTime) {
+		t.Errorf("expected absence end time %v, got %v", expectedEndTime, endTime)
+	}
+}
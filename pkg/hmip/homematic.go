@@ -2,6 +2,7 @@ package hmip
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -75,25 +76,44 @@ func (c *homematic) LoadCurrentState() (State, error) {
 			return err
 		}
 		if response.StatusCode != 200 {
-			return errors.New(fmt.Sprintf("Error on reading state (%s)", response.Status))
+			_ = response.Body.Close()
+			return responseError(response, "reading state")
 		}
 		return nil
 	}, retry.OnRetry(func(_ uint, _ error) {
 		_ = c.config.lookupEndpoints()
-	}), retry.Attempts(2))
+	}), retry.RetryIf(isRecoverable), retry.Attempts(2))
+	if errors.Is(err, ErrClientRevoked) {
+		return nil, ErrClientRevoked
+	}
 	if err != nil {
 		return nil, err
 	}
 	defer func(Body io.ReadCloser) {
 		_ = Body.Close()
 	}(response.Body)
-	responseBody, _ := io.ReadAll(response.Body)
-	state := state{}
-	err = json.Unmarshal(responseBody, &state)
+	return ReadStateWithOptions(response.Body, c.config.getDecodeOptions())
+}
+
+func (c *homematic) VerifyCredentials(ctx context.Context) error {
+	requestBody, _ := json.Marshal(getStateRequest{
+		ClientCharacteristics: c.config.getClientCharacteristics(),
+	})
+	request, err := http.NewRequestWithContext(ctx, "POST", c.config.RestEndpoint+"/hmip/home/getCurrentState", bytes.NewReader(requestBody))
 	if err != nil {
-		return nil, err
+		return err
+	}
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(response.Body)
+	if response.StatusCode != 200 {
+		return responseError(response, "verifying credentials")
 	}
-	return &state, nil
+	return nil
 }
 
 func (c *homematic) RegisterEventHandler(handler EventHandler, eventTypes ...string) {
@@ -112,7 +132,7 @@ func (c *homematic) ListenForEvents() error {
 		return errors.New("Event loop already running")
 	}
 	c.eventLoopRunning = true
-	return retry.Do(c.eventLoop, retry.DelayType(func(n uint, loopErr error, config *retry.Config) time.Duration {
+	err := retry.Do(c.eventLoop, retry.DelayType(func(n uint, loopErr error, config *retry.Config) time.Duration {
 		c.eventLoopError = loopErr
 		_, _ = fmt.Fprintf(c.eventLog, "Error in event loop: %v\nTry to lookup hosts again\n", loopErr)
 		err := c.config.lookupEndpoints()
@@ -131,7 +151,13 @@ func (c *homematic) ListenForEvents() error {
 		}
 		_, _ = fmt.Fprintf(c.eventLog, "Restarting event loop in 10 minutes\n")
 		return time.Minute * 10
-	}), retry.Attempts(0))
+	}), retry.RetryIf(isRecoverable), retry.Attempts(0))
+	if errors.Is(err, ErrClientRevoked) {
+		c.eventLoopError = ErrClientRevoked
+		c.eventLoopRunning = false
+		_, _ = fmt.Fprintf(c.eventLog, "Error in event loop: %v\nStopping event loop\n", err)
+	}
+	return err
 }
 
 func (c *homematic) eventLoop() error {
@@ -139,6 +165,14 @@ func (c *homematic) eventLoop() error {
 	var err error
 	c.websocketConnection, err = websocket.DialConfig(c.websocketConfig)
 	if err != nil {
+		// The websocket handshake does not expose the HTTP status, so a rejected
+		// handshake is checked against the REST API to detect a revoked client.
+		var dialErr *websocket.DialError
+		if errors.As(err, &dialErr) && dialErr.Err == websocket.ErrBadStatus {
+			if verifyErr := c.VerifyCredentials(context.Background()); errors.Is(verifyErr, ErrClientRevoked) {
+				return verifyErr
+			}
+		}
 		return err
 	}
 	defer func(conn *websocket.Conn) {
@@ -147,8 +181,8 @@ func (c *homematic) eventLoop() error {
 	}(c.websocketConnection)
 	_, _ = fmt.Fprintf(c.eventLog, "\U0001F50C Established connection to %v\n", c.websocketConnection.RemoteAddr())
 	for {
-		message := pushMessage{}
-		err := websocket.JSON.Receive(c.websocketConnection, &message)
+		var data []byte
+		err := websocket.Message.Receive(c.websocketConnection, &data)
 		if err != nil {
 			if c.eventLoopRunning {
 				return err
@@ -156,6 +190,13 @@ func (c *homematic) eventLoop() error {
 				return nil // Error occurred because of terminating the event loop - returning without error
 			}
 		}
+		message, warnings, err := decodePushMessage(data, c.config.getDecodeOptions())
+		if err != nil {
+			return err
+		}
+		for _, warning := range warnings {
+			_, _ = fmt.Fprintf(c.eventLog, "\u26A0\uFE0F %s\n", warning)
+		}
 		for _, event := range message.Events {
 			for _, registration := range c.registrations {
 				if len(registration.Types) == 0 || slices.Contains(registration.Types, event.GetType()) {
@@ -180,6 +221,25 @@ func (c *homematic) StopEventListening() error {
 	return nil
 }
 
+// responseError creates the error for a failed request, mapping
+// rejected credentials to ErrClientRevoked.
+func responseError(response *http.Response, action string) error {
+	if response.StatusCode == http.StatusForbidden || response.StatusCode == http.StatusUnauthorized {
+		return ErrClientRevoked
+	}
+	return errors.New(fmt.Sprintf("Error on %s (%s)", action, response.Status))
+}
+
+// isRecoverable replaces the default predicate of retry-go, so it keeps
+// unrecoverable errors unrecoverable and additionally stops on ErrClientRevoked.
+func isRecoverable(err error) bool {
+	return retry.IsRecoverable(err) && isRecoverableResponseError(err)
+}
+
+func isRecoverableResponseError(err error) bool {
+	return !errors.Is(err, ErrClientRevoked)
+}
+
 type homematicRoundTripper struct {
 	Origin http.RoundTripper
 	config *Config
@@ -198,8 +258,37 @@ type getStateRequest struct {
 }
 
 type pushMessage struct {
-	Events Events `json:"events"`
-	Origin origin `json:"origin"`
+	Events Events
+	Origin origin
+}
+
+// decodePushMessage decodes a message received by the WebSocket connection and
+// returns the warnings of all skipped or partially decoded events in lenient mode.
+// The data is owned by the events afterwards.
+func decodePushMessage(data []byte, options DecodeOptions) (*pushMessage, []DecodeWarning, error) {
+	// The scanner only checks the structure, so the syntax is checked once for the whole message
+	if !json.Valid(data) {
+		return nil, nil, json.Unmarshal(data, new(any))
+	}
+	d := newDecoder(options)
+	message := pushMessage{}
+	var err error
+	scanner := newMemberScanner(data)
+	for scanner.next() && err == nil {
+		switch string(scanner.key) {
+		case "events":
+			message.Events, err = d.decodeEvents("events", scanner.value)
+		case "origin":
+			err = json.Unmarshal(scanner.value, &message.Origin)
+		}
+	}
+	if scanner.err != nil {
+		err = scanner.err
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return &message, d.warnings, nil
 }
 
 type handlerRegistration struct {
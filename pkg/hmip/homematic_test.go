@@ -0,0 +1,73 @@
+package hmip
+
+import (
+	"context"
+	"errors"
+	"github.com/avast/retry-go/v4"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestLoadCurrentStateRevokedClient(t *testing.T) {
+	for _, status := range []int{http.StatusUnauthorized, http.StatusForbidden} {
+		t.Run(http.StatusText(status), func(t *testing.T) {
+			var requests atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+				requests.Add(1)
+				writer.WriteHeader(status)
+			}))
+			defer server.Close()
+			client := &homematic{
+				config:     &Config{RestEndpoint: server.URL},
+				httpClient: server.Client(),
+			}
+
+			_, err := client.LoadCurrentState()
+
+			if !errors.Is(err, ErrClientRevoked) {
+				t.Errorf("expected ErrClientRevoked, got %v", err)
+			}
+			if requests.Load() != 1 {
+				t.Errorf("expected 1 request without retry, got %d", requests.Load())
+			}
+		})
+	}
+}
+
+func TestVerifyCredentialsRevokedClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+	client := &homematic{
+		config:     &Config{RestEndpoint: server.URL},
+		httpClient: server.Client(),
+	}
+
+	err := client.VerifyCredentials(context.Background())
+
+	if !errors.Is(err, ErrClientRevoked) {
+		t.Errorf("expected ErrClientRevoked, got %v", err)
+	}
+}
+
+func TestIsRecoverable(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		recoverable bool
+	}{
+		{"plain error", errors.New("timeout"), true},
+		{"revoked client", ErrClientRevoked, false},
+		{"unrecoverable error", retry.Unrecoverable(errors.New("invalid request")), false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if isRecoverable(test.err) != test.recoverable {
+				t.Errorf("expected recoverable to be %v", test.recoverable)
+			}
+		})
+	}
+}
@@ -0,0 +1,265 @@
+package hmip
+
+import "slices"
+
+// DevicePredicate is a condition for devices used in DeviceQuery.Where.
+type DevicePredicate func(state State, device Device) bool
+
+// ChannelPredicate is a condition for functional channels used in ChannelQuery.Where.
+type ChannelPredicate func(state State, channel FunctionalChannel) bool
+
+// ======================================================
+
+type deviceQuery struct {
+	state   State
+	devices Devices
+}
+
+// NewDeviceQuery creates a query over the given devices of the state.
+func NewDeviceQuery(state State, devices Devices) DeviceQuery {
+	return deviceQuery{
+		state:   state,
+		devices: devices,
+	}
+}
+
+func (q deviceQuery) Where(predicates ...DevicePredicate) DeviceQuery {
+	var devices Devices
+	for _, device := range q.devices {
+		if matchesAll(q.state, device, predicates) {
+			devices = append(devices, device)
+		}
+	}
+	return NewDeviceQuery(q.state, devices)
+}
+
+func (q deviceQuery) Channels(channelTypes ...string) ChannelQuery {
+	var channels FunctionalChannels
+	for _, device := range q.devices {
+		for _, channel := range device.GetFunctionalChannels() {
+			if len(channelTypes) == 0 || slices.Contains(channelTypes, channel.GetType()) {
+				channels = append(channels, channel)
+			}
+		}
+	}
+	return NewChannelQuery(q.state, channels)
+}
+
+func (q deviceQuery) All() Devices {
+	return q.devices
+}
+
+func (q deviceQuery) First() Device {
+	if len(q.devices) == 0 {
+		return nil
+	}
+	return q.devices[0]
+}
+
+func (q deviceQuery) Count() int {
+	return len(q.devices)
+}
+
+// ======================================================
+
+type channelQuery struct {
+	state    State
+	channels FunctionalChannels
+}
+
+// NewChannelQuery creates a query over the given functional channels of the state.
+func NewChannelQuery(state State, channels FunctionalChannels) ChannelQuery {
+	return channelQuery{
+		state:    state,
+		channels: channels,
+	}
+}
+
+func (q channelQuery) Where(predicates ...ChannelPredicate) ChannelQuery {
+	var channels FunctionalChannels
+	for _, channel := range q.channels {
+		if matchesAll(q.state, channel, predicates) {
+			channels = append(channels, channel)
+		}
+	}
+	return NewChannelQuery(q.state, channels)
+}
+
+func (q channelQuery) Devices() DeviceQuery {
+	var devices Devices
+	contained := make(map[string]bool)
+	for _, channel := range q.channels {
+		device := channel.GetDevice()
+		if device != nil && !contained[device.GetID()] {
+			contained[device.GetID()] = true
+			devices = append(devices, device)
+		}
+	}
+	return NewDeviceQuery(q.state, devices)
+}
+
+func (q channelQuery) All() FunctionalChannels {
+	return q.channels
+}
+
+func (q channelQuery) First() FunctionalChannel {
+	if len(q.channels) == 0 {
+		return nil
+	}
+	return q.channels[0]
+}
+
+func (q channelQuery) Count() int {
+	return len(q.channels)
+}
+
+func matchesAll[T any, P ~func(State, T) bool](state State, value T, predicates []P) bool {
+	for _, predicate := range predicates {
+		if !predicate(state, value) {
+			return false
+		}
+	}
+	return true
+}
+
+// ======================================================
+
+// InRoom matches devices in the room with the given name,
+// including devices contained in several rooms.
+func InRoom(roomName string) DevicePredicate {
+	return func(state State, device Device) bool {
+		for _, room := range state.GetRoomsOfDevice(device.GetID()) {
+			if room.GetName() == roomName {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// InGroup matches devices being member of the group with the given ID.
+func InGroup(groupID string) DevicePredicate {
+	return func(state State, device Device) bool {
+		for _, group := range state.GetGroupsOfDevice(device.GetID()) {
+			if group.GetID() == groupID {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// OfType matches devices with one of the given device types.
+func OfType(deviceTypes ...string) DevicePredicate {
+	return func(_ State, device Device) bool {
+		return slices.Contains(deviceTypes, device.GetType())
+	}
+}
+
+// OfModel matches devices with one of the given model types (e.g. HmIP-PSM).
+func OfModel(models ...string) DevicePredicate {
+	return func(_ State, device Device) bool {
+		return slices.Contains(models, device.GetModel())
+	}
+}
+
+// ConnectionType matches devices with the given connection type.
+func ConnectionType(connectionType string) DevicePredicate {
+	return func(_ State, device Device) bool {
+		return device.GetConnectionType() == connectionType
+	}
+}
+
+// LowBattery matches devices reporting a low battery.
+func LowBattery() DevicePredicate {
+	return hasBaseDeviceChannel(BaseDeviceChannel.HasLowBattery)
+}
+
+// Unreached matches devices not reachable by the access point.
+func Unreached() DevicePredicate {
+	return hasBaseDeviceChannel(BaseDeviceChannel.IsUnreached)
+}
+
+// UpdateAvailable matches devices with an available firmware update.
+func UpdateAvailable() DevicePredicate {
+	return func(_ State, device Device) bool {
+		return device.IsUpdateAvailable()
+	}
+}
+
+// NotDevice negates a device predicate.
+func NotDevice(predicate DevicePredicate) DevicePredicate {
+	return func(state State, device Device) bool {
+		return !predicate(state, device)
+	}
+}
+
+// AnyOf matches devices matching at least one of the given predicates.
+func AnyOf(predicates ...DevicePredicate) DevicePredicate {
+	return func(state State, device Device) bool {
+		for _, predicate := range predicates {
+			if predicate(state, device) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func hasBaseDeviceChannel(condition func(BaseDeviceChannel) bool) DevicePredicate {
+	return func(_ State, device Device) bool {
+		for _, channel := range device.GetFunctionalChannels() {
+			if baseChannel, ok := channel.(BaseDeviceChannel); ok && condition(baseChannel) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// ======================================================
+
+// ChannelInGroup matches functional channels assigned to the group with the given ID.
+func ChannelInGroup(groupID string) ChannelPredicate {
+	return func(_ State, channel FunctionalChannel) bool {
+		return slices.Contains(channel.GetGroupIDs(), groupID)
+	}
+}
+
+// SwitchedOn matches switchable functional channels being switched on.
+func SwitchedOn() ChannelPredicate {
+	return func(_ State, channel FunctionalChannel) bool {
+		switchable, ok := channel.(Switchable)
+		return ok && switchable.IsSwitchedOn()
+	}
+}
+
+// Open matches functional channels of windows and doors being open or tilted.
+func Open() ChannelPredicate {
+	return func(_ State, channel FunctionalChannel) bool {
+		openable, ok := channel.(Openable)
+		return ok && openable.IsOpen()
+	}
+}
+
+// Occupied matches functional channels of motion detectors detecting motion
+// and of presence sensors detecting presence.
+func Occupied() ChannelPredicate {
+	return func(_ State, channel FunctionalChannel) bool {
+		switch detector := channel.(type) {
+		case MotionDetectionChannel:
+			return detector.IsMotionDetected()
+		case PresenceDetectionChannel:
+			return detector.IsPresenceDetected()
+		default:
+			return false
+		}
+	}
+}
+
+// NotChannel negates a channel predicate.
+func NotChannel(predicate ChannelPredicate) ChannelPredicate {
+	return func(state State, channel FunctionalChannel) bool {
+		return !predicate(state, channel)
+	}
+}
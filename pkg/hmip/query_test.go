@@ -0,0 +1,115 @@
+package hmip
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+const (
+	coffeeMachineID = "3014F711A0000A9A4992D6F0"
+	balconySensorID = "3014F711A0000DDA4990A1C2"
+)
+
+func parseFixtureState(t *testing.T) State {
+	t.Helper()
+	state, err := ParseState(readFixture(t, "state.json"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return state
+}
+
+func TestDeviceQuery(t *testing.T) {
+	state := parseFixtureState(t)
+	tests := []struct {
+		name     string
+		query    DeviceQuery
+		expected int
+	}{
+		{"all devices", state.Devices(), 15},
+		{"in room", state.Devices().Where(InRoom("Küche")), 3},
+		{"in room with escaped name", state.Devices().Where(InRoom("Flur")), 4},
+		{"in group", state.Devices().Where(InGroup("g-heat-living")), 2},
+		{"of type", state.Devices().Where(OfType(DEVICE_TYPE_HEATING_THERMOSTAT, DEVICE_TYPE_WALL_MOUNTED_THERMOSTAT_PRO)), 2},
+		{"of model", state.Devices().Where(OfModel("HmIP-PSM")), 1},
+		{"connection type", state.Devices().Where(ConnectionType(CONNECTION_TYPE_RF)), 14},
+		{"low battery", state.Devices().Where(LowBattery()), 1},
+		{"update available", state.Devices().Where(UpdateAvailable()), 1},
+		{"not", state.Devices().Where(NotDevice(InRoom("Flur"))), 11},
+		{"any of", state.Devices().Where(AnyOf(InRoom("Küche"), InRoom("Büro"))), 5},
+		{"combined", state.Devices().Where(InRoom("Küche"), OfType(DEVICE_TYPE_PLUGABLE_SWITCH_MEASURING)), 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if count := test.query.Count(); count != test.expected {
+				t.Errorf("expected %d devices, got %d", test.expected, count)
+			}
+		})
+	}
+}
+
+func TestChannelQuery(t *testing.T) {
+	state := parseFixtureState(t)
+	tests := []struct {
+		name     string
+		query    ChannelQuery
+		expected int
+	}{
+		{"switched on", state.Devices().Channels().Where(SwitchedOn()), 2},
+		{"switched on of type", state.Devices().Channels(CHANNEL_TYPE_SWITCH_MEASURING).Where(SwitchedOn()), 1},
+		{"open", state.Devices().Channels().Where(Open()), 1},
+		{"not open", state.Devices().Channels(CHANNEL_TYPE_SHUTTER_CONTACT, CHANNEL_TYPE_ROTARY_HANDLE).Where(NotChannel(Open())), 1},
+		{"occupied", state.Devices().Channels().Where(Occupied()), 1},
+		{"in group", state.Devices().Channels().Where(ChannelInGroup("g-security-internal")), 2},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if count := test.query.Count(); count != test.expected {
+				t.Errorf("expected %d channels, got %d", test.expected, count)
+			}
+		})
+	}
+	if state.Devices().Channels().Where(Open()).Devices().First().GetName() != "Terrassentür" {
+		t.Error("expected the device of the open channel")
+	}
+}
+
+func TestApplyEvents(t *testing.T) {
+	state := parseFixtureState(t)
+	var events Events
+	err := json.Unmarshal([]byte(`{
+		"0":{"pushEventType":"DEVICE_CHANGED","device":{"id":"`+coffeeMachineID+`","type":"PLUGABLE_SWITCH_MEASURING","label":"Espresso",
+			"functionalChannels":{"1":{"functionalChannelType":"SWITCH_MEASURING_CHANNEL","index":1,"on":false,"groups":["g-meta-kitchen"]}}}},
+		"1":{"pushEventType":"GROUP_CHANGED","group":{"id":"g-meta-kitchen","type":"META","label":"Küche",
+			"channels":[{"deviceId":"`+coffeeMachineID+`","channelIndex":1},{"deviceId":"`+balconySensorID+`","channelIndex":1}]}},
+		"2":{"pushEventType":"HOME_CHANGED","home":{"id":"home-1","timeZoneId":"Europe/Vienna"}}}`), &events)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	live := state.ApplyEvents(events...)
+
+	if live.GetDeviceByID(coffeeMachineID).GetName() != "Espresso" {
+		t.Error("expected the changed device")
+	}
+	if live.Devices().Channels(CHANNEL_TYPE_SWITCH_MEASURING).Where(SwitchedOn()).Count() != 0 {
+		t.Error("expected the changed channel")
+	}
+	if live.Devices().Where(InRoom("Küche")).Count() != 2 || live.GetRoomOfDevice(balconySensorID).GetName() != "Balkon" {
+		t.Error("expected the changed members of the group")
+	}
+	if live.GetHome().GetTimeZoneID() != "Europe/Vienna" {
+		t.Error("expected the changed home")
+	}
+	if len(live.GetDevices()) != len(state.GetDevices()) {
+		t.Error("expected the changed device to be replaced")
+	}
+
+	// The original state is not changed
+	if state.GetDeviceByID(coffeeMachineID).GetName() != "Kaffeemaschine" || state.Devices().Where(InRoom("Küche")).Count() != 3 {
+		t.Error("expected the original state to be unchanged")
+	}
+	if len(state.GetGroupByID("g-meta-kitchen").GetMembers()) != 3 || state.GetHome().GetTimeZoneID() != "Europe/Berlin" {
+		t.Error("expected the original groups and home to be unchanged")
+	}
+}
@@ -0,0 +1,138 @@
+package hmip
+
+import "sync"
+
+// ChannelFactory creates the functional channel for a registered channel type.
+// Only the type, the raw JSON and the device of the base channel are set when the
+// factory is called, the common channel data is decoded together with the returned
+// channel afterwards. The returned channel should embed the base channel and declare
+// its additional attributes with JSON tags. States are marshalled by the raw JSON of
+// their entities (see Raw.GetRaw), so registered types need not implement MarshalJSON:
+//
+//	type valveChannel struct {
+//		hmip.FunctionalChannel `json:"-"`
+//		ValvePosition float64  `json:"valvePosition"`
+//	}
+//
+//	hmip.RegisterChannelType("VALVE_CHANNEL", func(base hmip.FunctionalChannel) hmip.FunctionalChannel {
+//		return &valveChannel{FunctionalChannel: base}
+//	})
+type ChannelFactory func(base FunctionalChannel) FunctionalChannel
+
+// DeviceFactory creates the device for a registered device type (see ChannelFactory).
+// The functional channels of the device refer to the returned device.
+type DeviceFactory func(base Device) Device
+
+// GroupFactory creates the group for a registered group type (see ChannelFactory).
+type GroupFactory func(base Group) Group
+
+// EventFactory creates the event for a registered event type (see ChannelFactory).
+type EventFactory func(base Event) Event
+
+var (
+	registryLock     sync.RWMutex
+	channelFactories = make(map[string]ChannelFactory)
+	deviceFactories  = make(map[string]DeviceFactory)
+	groupFactories   = make(map[string]GroupFactory)
+	eventFactories   = make(map[string]EventFactory)
+)
+
+// RegisterChannelType registers the factory used for decoding functional channels
+// of the given type, replacing the factory registered before. Channels of types
+// without a registered factory are decoded as plain FunctionalChannel.
+func RegisterChannelType(channelType string, factory ChannelFactory) {
+	registryLock.Lock()
+	defer registryLock.Unlock()
+	channelFactories[channelType] = factory
+}
+
+// RegisterDeviceType registers the factory used for decoding devices of the given type,
+// replacing the factory registered before. Devices of types without a registered factory
+// are decoded as plain Device.
+func RegisterDeviceType(deviceType string, factory DeviceFactory) {
+	registryLock.Lock()
+	defer registryLock.Unlock()
+	deviceFactories[deviceType] = factory
+}
+
+// RegisterGroupType registers the factory used for decoding groups of the given type,
+// replacing the factory registered before. Groups of types without a registered factory
+// are decoded as plain Group.
+func RegisterGroupType(groupType string, factory GroupFactory) {
+	registryLock.Lock()
+	defer registryLock.Unlock()
+	groupFactories[groupType] = factory
+}
+
+// RegisterEventType registers the factory used for decoding events of the given type,
+// replacing the factory registered before. Events of types without a registered factory
+// are decoded as plain Event.
+func RegisterEventType(eventType string, factory EventFactory) {
+	registryLock.Lock()
+	defer registryLock.Unlock()
+	eventFactories[eventType] = factory
+}
+
+func init() {
+	RegisterChannelType(CHANNEL_TYPE_DEVICE_BASE, func(base FunctionalChannel) FunctionalChannel {
+		return &baseDeviceChannel{functionalChannel: base.(*functionalChannel)}
+	})
+	RegisterChannelType(CHANNEL_TYPE_SWITCH, func(base FunctionalChannel) FunctionalChannel {
+		return &switchChannel{functionalChannel: base.(*functionalChannel)}
+	})
+	RegisterChannelType(CHANNEL_TYPE_SWITCH_MEASURING, func(base FunctionalChannel) FunctionalChannel {
+		return &switchMeasuringChannel{functionalChannel: base.(*functionalChannel)}
+	})
+	RegisterChannelType(CHANNEL_TYPE_CLIMATE_SENSOR, func(base FunctionalChannel) FunctionalChannel {
+		return &climateSensorChannel{functionalChannel: base.(*functionalChannel)}
+	})
+	RegisterChannelType(CHANNEL_TYPE_SMOKE_DETECTOR, func(base FunctionalChannel) FunctionalChannel {
+		return &smokeDetectorChannel{functionalChannel: base.(*functionalChannel)}
+	})
+	RegisterChannelType(CHANNEL_TYPE_HEATING_THERMOSTAT, func(base FunctionalChannel) FunctionalChannel {
+		return &heatingThermostatChannel{functionalChannel: base.(*functionalChannel)}
+	})
+	RegisterChannelType(CHANNEL_TYPE_WALL_MOUNTED_THERMOSTAT_WITHOUT_DISPLAY, func(base FunctionalChannel) FunctionalChannel {
+		return &wallMountedThermostatChannel{functionalChannel: base.(*functionalChannel)}
+	})
+	RegisterChannelType(CHANNEL_TYPE_WALL_MOUNTED_THERMOSTAT_PRO, func(base FunctionalChannel) FunctionalChannel {
+		return &wallMountedThermostatProChannel{wallMountedThermostatChannel: wallMountedThermostatChannel{functionalChannel: base.(*functionalChannel)}}
+	})
+	RegisterChannelType(CHANNEL_TYPE_SHUTTER, func(base FunctionalChannel) FunctionalChannel {
+		return &shutterChannel{functionalChannel: base.(*functionalChannel)}
+	})
+	RegisterChannelType(CHANNEL_TYPE_BLIND, func(base FunctionalChannel) FunctionalChannel {
+		return &blindChannel{shutterChannel: shutterChannel{functionalChannel: base.(*functionalChannel)}}
+	})
+	RegisterChannelType(CHANNEL_TYPE_DIMMER, func(base FunctionalChannel) FunctionalChannel {
+		return &dimmerChannel{functionalChannel: base.(*functionalChannel)}
+	})
+	RegisterChannelType(CHANNEL_TYPE_NOTIFICATION_LIGHT, func(base FunctionalChannel) FunctionalChannel {
+		return &notificationLightChannel{functionalChannel: base.(*functionalChannel)}
+	})
+	for _, channelType := range []string{CHANNEL_TYPE_SHUTTER_CONTACT, CHANNEL_TYPE_ROTARY_HANDLE, CHANNEL_TYPE_CONTACT_INTERFACE} {
+		RegisterChannelType(channelType, func(base FunctionalChannel) FunctionalChannel {
+			return &contactChannel{functionalChannel: base.(*functionalChannel)}
+		})
+	}
+	RegisterChannelType(CHANNEL_TYPE_MOTION_DETECTION, func(base FunctionalChannel) FunctionalChannel {
+		return &motionDetectionChannel{functionalChannel: base.(*functionalChannel)}
+	})
+	RegisterChannelType(CHANNEL_TYPE_PRESENCE_DETECTION, func(base FunctionalChannel) FunctionalChannel {
+		return &presenceDetectionChannel{functionalChannel: base.(*functionalChannel)}
+	})
+
+	RegisterGroupType(GROUP_TYPE_META, func(base Group) Group {
+		return &metaGroup{group: base.(*group)}
+	})
+
+	RegisterEventType(EVENT_TYPE_DEVICE_CHANGED, func(base Event) Event {
+		return &deviceChangedEvent{event: base.(*event)}
+	})
+	RegisterEventType(EVENT_TYPE_GROUP_CHANGED, func(base Event) Event {
+		return &groupChangedEvent{event: base.(*event)}
+	})
+	RegisterEventType(EVENT_TYPE_HOME_CHANGED, func(base Event) Event {
+		return &homeChangedEvent{event: base.(*event)}
+	})
+}
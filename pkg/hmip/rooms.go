@@ -0,0 +1,149 @@
+package hmip
+
+import "slices"
+
+type room struct {
+	metaGroup MetaGroup
+	state     *state
+}
+
+func (r room) GetID() string {
+	return r.metaGroup.GetID()
+}
+
+func (r room) GetName() string {
+	return r.metaGroup.GetName()
+}
+
+func (r room) GetIcon() string {
+	return r.metaGroup.GetIcon()
+}
+
+func (r room) GetMetaGroup() MetaGroup {
+	return r.metaGroup
+}
+
+func (r room) GetDevices() Devices {
+	var devices Devices
+	contained := make(map[string]bool)
+	for _, member := range r.metaGroup.GetMembers() {
+		device := member.GetDevice()
+		if !contained[device.GetID()] {
+			contained[device.GetID()] = true
+			devices = append(devices, device)
+		}
+	}
+	sortByName(devices)
+	return devices
+}
+
+func (r room) GetSubGroups() Groups {
+	var groups Groups
+	for _, groupID := range r.metaGroup.GetGroupIDs() {
+		if group := r.state.GetGroupByID(groupID); group != nil {
+			groups = append(groups, group)
+		}
+	}
+	sortByName(groups)
+	return groups
+}
+
+func (r room) GetHeatingGroups() Groups {
+	return r.getSubGroupsByType(GROUP_TYPE_HEATING)
+}
+
+func (r room) GetSecurityGroups() Groups {
+	return r.getSubGroupsByType(GROUP_TYPE_SECURITY, GROUP_TYPE_SECURITY_ZONE)
+}
+
+func (r room) GetActualTemperature() (float64, bool) {
+	var temperatures []float64
+	for _, channel := range r.getChannels() {
+		if climateMeasuring, ok := channel.(ClimateMeasuring); ok {
+			temperatures = append(temperatures, climateMeasuring.GetActualTemperature())
+		}
+	}
+	if len(temperatures) == 0 {
+		// Fallback to the temperature reported by the heating groups
+		for _, group := range r.GetHeatingGroups() {
+			if temperature, ok := group.GetAttribute("actualTemperature").(float64); ok {
+				temperatures = append(temperatures, temperature)
+			}
+		}
+	}
+	return average(temperatures)
+}
+
+func (r room) GetHumidity() (int, bool) {
+	var humidities []float64
+	for _, channel := range r.getChannels() {
+		if climateMeasuring, ok := channel.(ClimateMeasuring); ok {
+			humidities = append(humidities, float64(climateMeasuring.GetHumidity()))
+		}
+	}
+	if len(humidities) == 0 {
+		// Fallback to the humidity reported by the heating groups
+		for _, group := range r.GetHeatingGroups() {
+			if humidity, ok := group.GetAttribute("humidity").(float64); ok {
+				humidities = append(humidities, humidity)
+			}
+		}
+	}
+	humidity, ok := average(humidities)
+	return int(humidity + 0.5), ok
+}
+
+func (r room) GetOpenWindows() FunctionalChannels {
+	var channels FunctionalChannels
+	for _, channel := range r.getChannels() {
+		if openable, ok := channel.(Openable); ok && openable.IsOpen() {
+			channels = append(channels, channel)
+		}
+	}
+	return channels
+}
+
+func (r room) GetActiveSwitches() FunctionalChannels {
+	var channels FunctionalChannels
+	for _, channel := range r.getChannels() {
+		if switchable, ok := channel.(Switchable); ok && switchable.IsSwitchedOn() {
+			channels = append(channels, channel)
+		}
+	}
+	return channels
+}
+
+func (r room) getSubGroupsByType(groupTypes ...string) Groups {
+	var groups Groups
+	for _, group := range r.GetSubGroups() {
+		for _, groupType := range groupTypes {
+			if group.GetType() == groupType {
+				groups = append(groups, group)
+			}
+		}
+	}
+	return groups
+}
+
+// getChannels returns the channels listed as members of the META group, so devices
+// with channels in several rooms contribute only their channels in this room.
+func (r room) getChannels() FunctionalChannels {
+	var channels FunctionalChannels
+	for _, member := range r.metaGroup.GetMembers() {
+		if !slices.Contains(channels, member.GetChannel()) {
+			channels = append(channels, member.GetChannel())
+		}
+	}
+	return channels
+}
+
+func average(values []float64) (float64, bool) {
+	if len(values) == 0 {
+		return 0, false
+	}
+	var sum float64
+	for _, value := range values {
+		sum += value
+	}
+	return sum / float64(len(values)), true
+}
@@ -0,0 +1,112 @@
+package hmip
+
+import (
+	"testing"
+)
+
+func TestRoomsOfDevice(t *testing.T) {
+	state, err := ParseState([]byte(`{
+		"devices":{"device-1":{"id":"device-1","type":"PLUGABLE_SWITCH","label":"Lamp",
+			"functionalChannels":{"1":{"functionalChannelType":"SWITCH_CHANNEL","index":1,"groups":["meta-b","meta-a"]}}}},
+		"groups":{
+			"meta-b":{"id":"meta-b","type":"META","label":"Hall","channels":[{"deviceId":"device-1","channelIndex":1}]},
+			"meta-a":{"id":"meta-a","type":"META","label":"Stairs","channels":[{"deviceId":"device-1","channelIndex":1}]}}}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	rooms := state.GetRoomsOfDevice("device-1")
+	if len(rooms) != 2 || rooms[0].GetName() != "Hall" || rooms[1].GetName() != "Stairs" {
+		t.Fatalf("expected both rooms ordered by name, got %v", rooms)
+	}
+	if room := state.GetRoomOfDevice("device-1"); room == nil || room.GetID() != "meta-a" {
+		t.Errorf("expected the room with the lowest ID, got %v", room)
+	}
+	if state.GetRoomOfDevice("device-2") != nil {
+		t.Error("expected no room for unknown device")
+	}
+	for _, roomName := range []string{"Hall", "Stairs"} {
+		if state.Devices().Where(InRoom(roomName)).Count() != 1 {
+			t.Errorf("expected the device in room %s", roomName)
+		}
+	}
+}
+
+func TestRoomReadings(t *testing.T) {
+	state := parseFixtureState(t)
+	rooms := make(map[string]Room)
+	for _, room := range state.GetRooms() {
+		rooms[room.GetName()] = room
+	}
+
+	kitchen := rooms["Küche"]
+	if temperature, ok := kitchen.GetActualTemperature(); !ok || temperature != 20.8 {
+		t.Errorf("expected the temperature of the wall thermostat, got %f (%v)", temperature, ok)
+	}
+	if humidity, ok := kitchen.GetHumidity(); !ok || humidity != 48 {
+		t.Errorf("expected the humidity of the wall thermostat, got %d (%v)", humidity, ok)
+	}
+	if switches := kitchen.GetActiveSwitches(); len(switches) != 1 || switches[0].GetDevice().GetID() != coffeeMachineID {
+		t.Errorf("expected the coffee machine as active switch, got %v", switches)
+	}
+	if len(kitchen.GetHeatingGroups()) != 1 || len(kitchen.GetOpenWindows()) != 0 {
+		t.Error("expected the heating group and no open window in the kitchen")
+	}
+
+	living := rooms["Wohnzimmer"]
+	if windows := living.GetOpenWindows(); len(windows) != 1 || windows[0].GetDevice().GetName() != "Terrassentür" {
+		t.Errorf("expected the tilted door as open window, got %v", windows)
+	}
+	if _, ok := living.GetActualTemperature(); ok {
+		t.Error("expected no temperature without climate channels and heating group readings")
+	}
+	if _, ok := living.GetHumidity(); ok {
+		t.Error("expected no humidity without climate channels and heating group readings")
+	}
+}
+
+func TestRoomReadingsOfMemberChannels(t *testing.T) {
+	state, err := ParseState([]byte(`{
+		"devices":{
+			"switch-2":{"id":"switch-2","type":"DIN_RAIL_SWITCH","label":"Actuator","functionalChannels":{
+				"1":{"functionalChannelType":"SWITCH_CHANNEL","index":1,"on":true,"groups":["kitchen"]},
+				"2":{"functionalChannelType":"SWITCH_CHANNEL","index":2,"on":true,"groups":["hall"]},
+				"3":{"functionalChannelType":"SWITCH_CHANNEL","index":3,"on":true,"groups":[]}}},
+			"sensor":{"id":"sensor","type":"TEMPERATURE_HUMIDITY_SENSOR","label":"Sensor","functionalChannels":{
+				"1":{"functionalChannelType":"CLIMATE_SENSOR_CHANNEL","index":1,"actualTemperature":20.0,"humidity":40,"groups":["kitchen"]}}},
+			"sensor-2":{"id":"sensor-2","type":"TEMPERATURE_HUMIDITY_SENSOR","label":"Sensor 2","functionalChannels":{
+				"1":{"functionalChannelType":"CLIMATE_SENSOR_CHANNEL","index":1,"actualTemperature":21.0,"humidity":45,"groups":["kitchen"]}}}},
+		"groups":{
+			"kitchen":{"id":"kitchen","type":"META","label":"Kitchen","groups":["heating-hall"],
+				"channels":[{"deviceId":"switch-2","channelIndex":1},{"deviceId":"sensor","channelIndex":1},{"deviceId":"sensor-2","channelIndex":1}]},
+			"hall":{"id":"hall","type":"META","label":"Hall","groups":["heating-hall"],
+				"channels":[{"deviceId":"switch-2","channelIndex":2}]},
+			"heating-hall":{"id":"heating-hall","type":"HEATING","label":"Heating","actualTemperature":18.5,"humidity":55.4,
+				"channels":[]}}}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	rooms := make(map[string]Room)
+	for _, room := range state.GetRooms() {
+		rooms[room.GetName()] = room
+	}
+
+	for _, roomName := range []string{"Kitchen", "Hall"} {
+		if switches := rooms[roomName].GetActiveSwitches(); len(switches) != 1 {
+			t.Errorf("expected one active switch in %s, got %d", roomName, len(switches))
+		}
+	}
+	if temperature, ok := rooms["Kitchen"].GetActualTemperature(); !ok || temperature != 20.5 {
+		t.Errorf("expected the average temperature of the sensors, got %f (%v)", temperature, ok)
+	}
+	if humidity, ok := rooms["Kitchen"].GetHumidity(); !ok || humidity != 43 {
+		t.Errorf("expected the rounded average humidity of the sensors, got %d (%v)", humidity, ok)
+	}
+	// The hall has no climate channels, so the readings of the heating group are used
+	if temperature, ok := rooms["Hall"].GetActualTemperature(); !ok || temperature != 18.5 {
+		t.Errorf("expected the temperature of the heating group, got %f (%v)", temperature, ok)
+	}
+	if humidity, ok := rooms["Hall"].GetHumidity(); !ok || humidity != 55 {
+		t.Errorf("expected the humidity of the heating group, got %d (%v)", humidity, ok)
+	}
+}
@@ -0,0 +1,250 @@
+package hmip
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// memberScanner iterates over the members of a JSON object in document order without
+// decoding them. Keys and values are sub slices of the scanned data, so nothing is copied
+// except for keys containing escape sequences, which are unescaped. The values are only checked for matching brackets and quotes, decoding them reports
+// all other syntax errors.
+type memberScanner struct {
+	data    []byte
+	pos     int
+	members int
+	done    bool
+	// start is the offset of the key of the current member, which ends at pos
+	start int
+	key   []byte
+	value []byte
+	err   error
+}
+
+func newMemberScanner(data []byte) memberScanner {
+	s := memberScanner{data: data}
+	s.skipSpace()
+	switch {
+	case bytes.Equal(bytes.TrimSpace(data), []byte("null")):
+		s.done = true
+	case s.pos < len(data) && data[s.pos] == '{':
+		s.pos++
+	default:
+		s.fail("expected object")
+	}
+	return s
+}
+
+// next moves to the next member and returns false at the end of the object or on errors.
+func (s *memberScanner) next() bool {
+	if s.err != nil || s.done {
+		return false
+	}
+	s.skipSpace()
+	if s.pos < len(s.data) && s.data[s.pos] == ',' && s.members > 0 {
+		s.pos++
+		s.skipSpace()
+	} else if s.pos < len(s.data) && s.data[s.pos] == '}' {
+		s.done = true
+		return false
+	} else if s.members > 0 {
+		s.fail("expected , or }")
+		return false
+	}
+	if s.pos >= len(s.data) || s.data[s.pos] != '"' {
+		s.fail("expected key")
+		return false
+	}
+	s.start = s.pos
+	end, ok := skipString(s.data, s.pos)
+	if !ok {
+		s.fail("unterminated key")
+		return false
+	}
+	s.key = s.data[s.pos+1 : end-1]
+	if bytes.IndexByte(s.key, '\\') >= 0 {
+		var key string
+		if json.Unmarshal(s.data[s.pos:end], &key) != nil {
+			s.fail("invalid key")
+			return false
+		}
+		s.key = []byte(key)
+	}
+	s.pos = end
+	s.skipSpace()
+	if s.pos >= len(s.data) || s.data[s.pos] != ':' {
+		s.fail("expected :")
+		return false
+	}
+	s.pos++
+	s.skipSpace()
+	end, ok = skipValue(s.data, s.pos)
+	if !ok {
+		s.fail("invalid value")
+		return false
+	}
+	s.value = s.data[s.pos:end]
+	s.pos = end
+	s.members++
+	return true
+}
+
+// keyString returns the unescaped key of the current member.
+func (s *memberScanner) keyString() string {
+	return string(s.key)
+}
+
+func (s *memberScanner) skipSpace() {
+	for s.pos < len(s.data) && isSpace(s.data[s.pos]) {
+		s.pos++
+	}
+}
+
+func (s *memberScanner) fail(message string) {
+	s.err = errors.New(fmt.Sprintf("Error on scanning JSON object (%s at offset %d)", message, s.pos))
+}
+
+// peekString returns the string value of the top level member with the given key
+// or an empty string if the member is missing or null.
+func peekString(data []byte, key string) (string, error) {
+	scanner := newMemberScanner(data)
+	for scanner.next() {
+		if string(scanner.key) == key {
+			return unquote(key, scanner.value)
+		}
+	}
+	return "", scanner.err
+}
+
+// peekMember returns the raw value of the top level member with the given key or nil if it is missing.
+func peekMember(data []byte, key string) ([]byte, error) {
+	scanner := newMemberScanner(data)
+	for scanner.next() {
+		if string(scanner.key) == key {
+			return scanner.value, nil
+		}
+	}
+	return nil, scanner.err
+}
+
+// peekRawString returns the content of the string value of the top level member with the given key
+// like peekString, but without copying it unless it contains escape sequences.
+func peekRawString(data []byte, key string) ([]byte, error) {
+	value, err := peekMember(data, key)
+	if err != nil || value == nil {
+		return nil, err
+	}
+	return rawString(key, value)
+}
+
+// rawString returns the content of the string value as sub slice of the value. Strings
+// with escape sequences are unquoted into a new slice.
+func rawString(key string, value []byte) ([]byte, error) {
+	if len(value) >= 2 && value[0] == '"' && bytes.IndexByte(value, '\\') < 0 {
+		return value[1 : len(value)-1], nil
+	}
+	result, err := unquote(key, value)
+	return []byte(result), err
+}
+
+// withoutMember appends the object without the member from start to end (including
+// the comma separating it from the other members) to the buffer.
+func withoutMember(buffer, object []byte, start, end int) []byte {
+	next := end
+	for next < len(object) && isSpace(object[next]) {
+		next++
+	}
+	if next < len(object) && object[next] == ',' {
+		end = next + 1
+	} else {
+		previous := start - 1
+		for previous >= 0 && isSpace(object[previous]) {
+			previous--
+		}
+		if previous >= 0 && object[previous] == ',' {
+			start = previous
+		}
+	}
+	buffer = append(buffer, object[:start]...)
+	return append(buffer, object[end:]...)
+}
+
+func unquote(key string, value []byte) (string, error) {
+	switch {
+	case string(value) == "null":
+		return "", nil
+	case len(value) < 2 || value[0] != '"':
+		return "", errors.New(fmt.Sprintf("Error on decoding %s (expected string, got %s)", key, value))
+	case bytes.IndexByte(value, '\\') < 0:
+		return string(value[1 : len(value)-1]), nil
+	}
+	var result string
+	err := json.Unmarshal(value, &result)
+	return result, err
+}
+
+// ======================================================
+
+// skipValue returns the end of the value starting at the position.
+func skipValue(data []byte, pos int) (int, bool) {
+	if pos >= len(data) {
+		return pos, false
+	}
+	switch data[pos] {
+	case '"':
+		return skipString(data, pos)
+	case '{', '[':
+		depth := 0
+		for pos < len(data) {
+			switch data[pos] {
+			case '"':
+				end, ok := skipString(data, pos)
+				if !ok {
+					return end, false
+				}
+				pos = end
+				continue
+			case '{', '[':
+				depth++
+			case '}', ']':
+				depth--
+				if depth == 0 {
+					return pos + 1, true
+				}
+			}
+			pos++
+		}
+		return pos, false
+	default:
+		start := pos
+		for pos < len(data) && !isSpace(data[pos]) && data[pos] != ',' && data[pos] != '}' && data[pos] != ']' {
+			pos++
+		}
+		return pos, pos > start
+	}
+}
+
+// skipString returns the end of the string starting with the quote at the position.
+func skipString(data []byte, pos int) (int, bool) {
+	for {
+		end := bytes.IndexByte(data[pos+1:], '"')
+		if end < 0 {
+			return len(data), false
+		}
+		pos += end + 1
+		// The quote is escaped by an odd number of backslashes before it
+		backslashes := 0
+		for data[pos-1-backslashes] == '\\' {
+			backslashes++
+		}
+		if backslashes%2 == 0 {
+			return pos + 1, true
+		}
+	}
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
@@ -0,0 +1,160 @@
+package hmip
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestMemberScanner(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		keys   []string
+		values []string
+		valid  bool
+	}{
+		{"empty object", `{}`, nil, nil, true},
+		{"null", ` null `, nil, nil, true},
+		{"strings", `{"a":"x","b":""}`, []string{"a", "b"}, []string{`"x"`, `""`}, true},
+		{"escaped quotes", `{"a":"say \"hi\"","b":"\\","c":"\\\""}`, []string{"a", "b", "c"}, []string{`"say \"hi\""`, `"\\"`, `"\\\""`}, true},
+		{"unicode escapes", `{"typ\u0065":"K\u00fcche","\"quoted\"":1}`, []string{"type", `"quoted"`}, []string{`"K\u00fcche"`, `1`}, true},
+		{"numbers", `{"a":1,"b":-2.5,"c":1e-7,"d":0}`, []string{"a", "b", "c", "d"}, []string{"1", "-2.5", "1e-7", "0"}, true},
+		{"literals", `{"a":true,"b":false,"c":null}`, []string{"a", "b", "c"}, []string{"true", "false", "null"}, true},
+		{"nested", `{"a":{"b":[1,{"c":[]}]},"d":[[],[{}]]}`, []string{"a", "d"}, []string{`{"b":[1,{"c":[]}]}`, `[[],[{}]]`}, true},
+		{"brackets in strings", `{"a":{"b":"}]{["},"c":["]"]}`, []string{"a", "c"}, []string{`{"b":"}]{["}`, `["]"]`}, true},
+		{"whitespace", " \t\r\n{ \"a\" :\n\t1 ,\r\n \"b\" : [ 1 , 2 ] \n}\n", []string{"a", "b"}, []string{"1", "[ 1 , 2 ]"}, true},
+		{"truncated object", `{"a":1`, []string{"a"}, []string{"1"}, false},
+		{"truncated string", `{"a":"x`, nil, nil, false},
+		{"truncated nested value", `{"a":{"b":[1,2}`, nil, nil, false},
+		{"truncated key", `{"a`, nil, nil, false},
+		{"missing colon", `{"a" 1}`, nil, nil, false},
+		{"missing value", `{"a":}`, nil, nil, false},
+		{"missing comma", `{"a":1 "b":2}`, []string{"a"}, []string{"1"}, false},
+		{"trailing comma", `{"a":1,}`, []string{"a"}, []string{"1"}, false},
+		{"array", `[1,2]`, nil, nil, false},
+		{"empty input", ``, nil, nil, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var keys, values []string
+			scanner := newMemberScanner([]byte(test.data))
+			for scanner.next() {
+				keys = append(keys, scanner.keyString())
+				values = append(values, string(scanner.value))
+			}
+			if test.valid && scanner.err != nil {
+				t.Fatalf("unexpected error: %v", scanner.err)
+			}
+			if !test.valid && scanner.err == nil {
+				t.Fatal("expected error")
+			}
+			if !slices.Equal(keys, test.keys) {
+				t.Errorf("expected keys %q, got %q", test.keys, keys)
+			}
+			if !slices.Equal(values, test.values) {
+				t.Errorf("expected values %q, got %q", test.values, values)
+			}
+		})
+	}
+}
+
+func TestPeekString(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		expected string
+		valid    bool
+	}{
+		{"plain", `{"id":"1","type":"SWITCH_CHANNEL"}`, "SWITCH_CHANNEL", true},
+		{"escaped value", `{"type":"SWITCH\u005fCHANNEL"}`, "SWITCH_CHANNEL", true},
+		{"escaped key", `{"id":"1","typ\u0065":"SWITCH_CHANNEL"}`, "SWITCH_CHANNEL", true},
+		{"nested key is ignored", `{"a":{"type":"NESTED"},"type":"TOP"}`, "TOP", true},
+		{"missing", `{"id":"1"}`, "", true},
+		{"null", `{"type":null}`, "", true},
+		{"number", `{"type":1}`, "", false},
+		{"truncated", `{"id":"1","ty`, "", false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			value, err := peekString([]byte(test.data), "type")
+			if test.valid && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !test.valid && err == nil {
+				t.Fatal("expected error")
+			}
+			if value != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, value)
+			}
+		})
+	}
+}
+
+func TestPeekMember(t *testing.T) {
+	value, err := peekMember([]byte(`{"pushEventType":"DEVICE_CHANGED","device":{"id":"1"}}`), "device")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(value) != `{"id":"1"}` {
+		t.Errorf("unexpected value %s", value)
+	}
+	value, err = peekMember([]byte(`{"pushEventType":"DEVICE_CHANGED"}`), "device")
+	if err != nil || value != nil {
+		t.Errorf("expected missing member, got %s (%v)", value, err)
+	}
+}
+
+func TestWithoutMember(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		expected string
+	}{
+		{"only member", `{"x":[1]}`, `{}`},
+		{"first member", `{"x":{"a":1},"b":2}`, `{"b":2}`},
+		{"middle member", `{"a":1, "x":"}" ,"b":2}`, `{"a":1, "b":2}`},
+		{"last member", `{"a":1 , "x":null }`, `{"a":1  }`},
+		{"escaped key", `{"a":1,"\u0078":2}`, `{"a":1}`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data := []byte(test.data)
+			scanner := newMemberScanner(data)
+			for scanner.next() && string(scanner.key) != "x" {
+			}
+			if scanner.err != nil || string(scanner.key) != "x" {
+				t.Fatalf("expected member x, got %q (%v)", scanner.key, scanner.err)
+			}
+			result := withoutMember(nil, data, scanner.start, scanner.pos)
+			if string(result) != test.expected {
+				t.Errorf("expected %s, got %s", test.expected, result)
+			}
+			if string(data) != test.data {
+				t.Error("expected the data to be unchanged")
+			}
+		})
+	}
+}
+
+func TestPeekRawString(t *testing.T) {
+	tests := []struct {
+		data     string
+		expected string
+		valid    bool
+	}{
+		{`{"type":"SWITCH"}`, "SWITCH", true},
+		{`{"type":"K\u00fcche"}`, "Küche", true},
+		{`{"type":null}`, "", true},
+		{`{"other":"SWITCH"}`, "", true},
+		{`{"type":1}`, "", false},
+	}
+	for _, test := range tests {
+		value, err := peekRawString([]byte(test.data), "type")
+		if (err == nil) != test.valid {
+			t.Errorf("unexpected error for %s: %v", test.data, err)
+		}
+		if string(value) != test.expected {
+			t.Errorf("expected %q for %s, got %q", test.expected, test.data, value)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package hmip
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
+
+const sgtinLength = 24
+
+// SGTIN is the serialized global trade item number identifying an access point or a device.
+// The canonical form consists of 24 upper case hex digits, the printed form on the devices
+// groups them in blocks of four separated by dashes (e.g. 3014-F711-A000-0000-0000-0001).
+type SGTIN string
+
+// ParseSGTIN parses the canonical or the printed form of an SGTIN
+// and returns the validated SGTIN in canonical form.
+func ParseSGTIN(value string) (SGTIN, error) {
+	sgtin := SGTIN(value).Canonical()
+	if err := sgtin.Validate(); err != nil {
+		return "", err
+	}
+	return sgtin, nil
+}
+
+// Canonical returns the SGTIN without separators in upper case.
+func (s SGTIN) Canonical() SGTIN {
+	replacer := strings.NewReplacer("-", "", " ", "")
+	return SGTIN(strings.ToUpper(replacer.Replace(strings.TrimSpace(string(s)))))
+}
+
+// Pretty returns the SGTIN in the printed form with dashes between blocks of four digits.
+// Invalid SGTINs are returned unchanged.
+func (s SGTIN) Pretty() string {
+	canonical := s.Canonical()
+	if canonical.Validate() != nil {
+		return string(s)
+	}
+	blocks := make([]string, 0, sgtinLength/4)
+	for i := 0; i < sgtinLength; i += 4 {
+		blocks = append(blocks, string(canonical[i:i+4]))
+	}
+	return strings.Join(blocks, "-")
+}
+
+// Validate checks that the SGTIN consists of 24 hex digits, ignoring separators.
+func (s SGTIN) Validate() error {
+	canonical := s.Canonical()
+	if len(canonical) != sgtinLength {
+		return errors.New(fmt.Sprintf("Invalid SGTIN %q (expected %d hex digits, got %d)", string(s), sgtinLength, len(canonical)))
+	}
+	for _, digit := range canonical {
+		if !strings.ContainsRune("0123456789ABCDEF", digit) {
+			return errors.New(fmt.Sprintf("Invalid SGTIN %q (unexpected character %q)", string(s), digit))
+		}
+	}
+	return nil
+}
+
+// IsValid reports whether the SGTIN consists of 24 hex digits, ignoring separators.
+func (s SGTIN) IsValid() bool {
+	return s.Validate() == nil
+}
+
+// GetModelFamily returns the model family registered for the longest prefix
+// matching the SGTIN or MODEL_FAMILY_UNKNOWN.
+func (s SGTIN) GetModelFamily() string {
+	canonical := string(s.Canonical())
+	modelFamiliesLock.RLock()
+	defer modelFamiliesLock.RUnlock()
+	for _, entry := range modelFamilies {
+		if strings.HasPrefix(canonical, entry.prefix) {
+			return entry.family
+		}
+	}
+	return MODEL_FAMILY_UNKNOWN
+}
+
+func (s SGTIN) String() string {
+	return s.Pretty()
+}
+
+// Set implements flag.Value, so an SGTIN can be used as command line flag.
+func (s *SGTIN) Set(value string) error {
+	sgtin, err := ParseSGTIN(value)
+	if err != nil {
+		return err
+	}
+	*s = sgtin
+	return nil
+}
+
+// ======================================================
+
+type modelFamily struct {
+	prefix string
+	family string
+}
+
+var (
+	modelFamiliesLock sync.RWMutex
+	// modelFamilies is sorted by descending prefix length, so the first match is the most specific.
+	modelFamilies = []modelFamily{
+		{prefix: "3014F711A061A7", family: MODEL_FAMILY_ACCESS_POINT},
+		{prefix: "3014F711A0", family: MODEL_FAMILY_HOMEMATIC_IP},
+		{prefix: "3014F711", family: MODEL_FAMILY_EQ3},
+	}
+)
+
+// RegisterModelFamily maps all SGTINs starting with the given prefix (in canonical or printed form)
+// to the model family, replacing the family registered before for the same prefix.
+// More specific prefixes take precedence over shorter ones.
+func RegisterModelFamily(prefix, family string) {
+	prefix = string(SGTIN(prefix).Canonical())
+	modelFamiliesLock.Lock()
+	defer modelFamiliesLock.Unlock()
+	for i, entry := range modelFamilies {
+		if entry.prefix == prefix {
+			modelFamilies[i].family = family
+			return
+		}
+	}
+	modelFamilies = append(modelFamilies, modelFamily{prefix: prefix, family: family})
+	slices.SortStableFunc(modelFamilies, func(a, b modelFamily) int {
+		return len(b.prefix) - len(a.prefix)
+	})
+}
@@ -0,0 +1,143 @@
+package hmip
+
+import (
+	"flag"
+	"io"
+	"slices"
+	"testing"
+)
+
+func TestParseSGTIN(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected SGTIN
+		valid    bool
+	}{
+		{"canonical", "3014F711A000000000000001", "3014F711A000000000000001", true},
+		{"printed", "3014-F711-A000-0000-0000-0001", "3014F711A000000000000001", true},
+		{"lower case", "3014f711a000000000000abc", "3014F711A000000000000ABC", true},
+		{"spaces", " 3014 F711 A000 0000 0000 0001 ", "3014F711A000000000000001", true},
+		{"empty", "", "", false},
+		{"too short", "3014-F711-A000-0000-0000", "", false},
+		{"too long", "3014F711A0000000000000011", "", false},
+		{"no hex digit", "3014F711A00000000000000G", "", false},
+		{"other separator", "3014_F711_A000_0000_0000_0001", "", false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sgtin, err := ParseSGTIN(test.value)
+			if test.valid && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !test.valid && err == nil {
+				t.Fatalf("expected error for %q", test.value)
+			}
+			if sgtin != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, sgtin)
+			}
+			if SGTIN(test.value).IsValid() != test.valid {
+				t.Errorf("expected IsValid to be %v", test.valid)
+			}
+		})
+	}
+}
+
+func TestSGTINPretty(t *testing.T) {
+	tests := []struct {
+		sgtin    SGTIN
+		expected string
+	}{
+		{"3014F711A000000000000001", "3014-F711-A000-0000-0000-0001"},
+		{"3014-f711-a000-0000-0000-0001", "3014-F711-A000-0000-0000-0001"},
+		{"invalid", "invalid"},
+	}
+	for _, test := range tests {
+		if pretty := test.sgtin.Pretty(); pretty != test.expected {
+			t.Errorf("expected %q for %q, got %q", test.expected, test.sgtin, pretty)
+		}
+		if str := test.sgtin.String(); str != test.expected {
+			t.Errorf("expected String() to return %q, got %q", test.expected, str)
+		}
+	}
+}
+
+func TestSGTINFlag(t *testing.T) {
+	var sgtin SGTIN
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags.Var(&sgtin, "sgtin", "")
+
+	if err := flags.Parse([]string{"--sgtin", "3014-F711-A000-0000-0000-0001"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sgtin != "3014F711A000000000000001" {
+		t.Errorf("expected canonical SGTIN, got %q", sgtin)
+	}
+	flags.SetOutput(io.Discard)
+	if err := flags.Parse([]string{"--sgtin", "3014"}); err == nil {
+		t.Error("expected error for invalid SGTIN")
+	}
+}
+
+func TestGetConfigInvalidSGTIN(t *testing.T) {
+	t.Setenv(EnvVarNameAccessPointSGTIN, "invalid")
+
+	config, err := GetConfig()
+
+	if err != nil {
+		t.Fatalf("expected the SGTIN to be validated later, got %v", err)
+	}
+	if config.AccessPointSGTIN.IsValid() {
+		t.Error("expected invalid SGTIN")
+	}
+}
+
+func TestSGTINModelFamily(t *testing.T) {
+	tests := []struct {
+		name     string
+		sgtin    SGTIN
+		expected string
+	}{
+		{"access point", "3014F711A061A7D8A99C1234", MODEL_FAMILY_ACCESS_POINT},
+		{"printed access point", "3014-f711-a061-a7d8-a99c-1234", MODEL_FAMILY_ACCESS_POINT},
+		{"device", "3014F711A0000A9A4992D6F0", MODEL_FAMILY_HOMEMATIC_IP},
+		{"other eQ-3 product", "3014F711B00000000000002A", MODEL_FAMILY_EQ3},
+		{"other company", "3014F712A000000000000001", MODEL_FAMILY_UNKNOWN},
+		{"prefix only", "3014F7", MODEL_FAMILY_UNKNOWN},
+		{"empty", "", MODEL_FAMILY_UNKNOWN},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if family := test.sgtin.GetModelFamily(); family != test.expected {
+				t.Errorf("expected %q for %q, got %q", test.expected, test.sgtin, family)
+			}
+		})
+	}
+}
+
+func TestRegisterModelFamily(t *testing.T) {
+	original := slices.Clone(modelFamilies)
+	t.Cleanup(func() { modelFamilies = original })
+
+	RegisterModelFamily("3014-f711-a000-0a", "COFFEE")
+	RegisterModelFamily("3014F711B0", "OTHER")
+	RegisterModelFamily("3014F711B0", "REPLACED")
+
+	tests := []struct {
+		sgtin    SGTIN
+		expected string
+	}{
+		{"3014F711A0000A9A4992D6F0", "COFFEE"},
+		{"3014F711A0000DDA4990A1C2", MODEL_FAMILY_HOMEMATIC_IP},
+		{"3014F711A061A7D8A99C1234", MODEL_FAMILY_ACCESS_POINT},
+		{"3014F711B00000000000002A", "REPLACED"},
+	}
+	for _, test := range tests {
+		if family := test.sgtin.GetModelFamily(); family != test.expected {
+			t.Errorf("expected %q for %q, got %q", test.expected, test.sgtin, family)
+		}
+	}
+	if len(modelFamilies) != len(original)+2 {
+		t.Errorf("expected the prefix registered twice to be replaced, got %v", modelFamilies)
+	}
+}
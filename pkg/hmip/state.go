@@ -1,26 +1,274 @@
 package hmip
 
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"io"
+	"slices"
+)
+
 type state struct {
-	Devices Devices `json:"devices"`
-	Groups  Groups  `json:"groups"`
-	Clients Clients `json:"clients"`
+	home    *home
+	devices Devices
+	groups  Groups
+	clients Clients
+	// extras contains all top level attributes not decoded into the fields above
+	extras      map[string]json.RawMessage
+	devicesByID map[string]Device
+	groupsByID  map[string]Group
+	clientsByID map[string]Client
+	// groupsByDeviceID contains the groups of a device in the order of groups
+	groupsByDeviceID map[string]Groups
+	rooms            Rooms
+	roomsByDeviceID  map[string]Rooms
+	warnings         []DecodeWarning
+}
+
+// ParseState decodes a state in the wire format of the HomematicIP Cloud,
+// as returned by Homematic.LoadCurrentState or by marshalling a State.
+func ParseState(data []byte) (State, error) {
+	return ParseStateWithOptions(data, DecodeOptions{})
+}
+
+// ParseStateWithOptions decodes a state like ParseState using the given options.
+func ParseStateWithOptions(data []byte, options DecodeOptions) (State, error) {
+	// The decoded entities keep their raw JSON, so the data is copied once for all of them
+	return parseState(bytes.Clone(data), options)
+}
+
+// parseState decodes the state in a single pass over the data, which is owned by the state afterwards.
+func parseState(data []byte, options DecodeOptions) (State, error) {
+	// The scanner only checks the structure, so the syntax is checked once for the whole state
+	if !json.Valid(data) {
+		return nil, json.Unmarshal(data, new(any))
+	}
+	d := newDecoder(options)
+	s := state{
+		extras: make(map[string]json.RawMessage),
+	}
+	var err error
+	scanner := newMemberScanner(data)
+	for scanner.next() && err == nil {
+		switch key := scanner.keyString(); key {
+		case "home":
+			s.home, err = d.decodeHome(key, scanner.value)
+		case "devices":
+			s.devices, err = d.decodeDevices(key, scanner.value)
+		case "groups":
+			s.groups, err = d.decodeGroups(key, scanner.value)
+		case "clients":
+			s.clients, err = d.decodeClients(key, scanner.value)
+		default:
+			s.extras[key] = scanner.value
+		}
+	}
+	if scanner.err != nil {
+		err = scanner.err
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.warnings = d.warnings
+	s.index()
+	return &s, nil
+}
+
+func (s *state) index() {
+	s.devicesByID = make(map[string]Device, len(s.devices))
+	for _, device := range s.devices {
+		s.devicesByID[device.GetID()] = device
+	}
+	s.groupsByID = make(map[string]Group, len(s.groups))
+	for _, group := range s.groups {
+		s.groupsByID[group.GetID()] = group
+		if boundGroup, ok := group.(stateBound); ok {
+			boundGroup.setState(s)
+		}
+	}
+	// Membership is taken from the members of the groups and from the groups of the channels.
+	// The groups are visited in their order, so the groups of each device keep this order.
+	deviceIDsByGroupID := make(map[string][]string)
+	for _, device := range s.devices {
+		for _, channel := range device.GetFunctionalChannels() {
+			for _, groupID := range channel.GetGroupIDs() {
+				deviceIDsByGroupID[groupID] = append(deviceIDsByGroupID[groupID], device.GetID())
+			}
+		}
+	}
+	s.groupsByDeviceID = make(map[string]Groups, len(s.devices))
+	addGroup := func(deviceID string, group Group) {
+		groups := s.groupsByDeviceID[deviceID]
+		if !slices.ContainsFunc(groups, func(other Group) bool { return other.GetID() == group.GetID() }) {
+			s.groupsByDeviceID[deviceID] = append(groups, group)
+		}
+	}
+	s.roomsByDeviceID = make(map[string]Rooms)
+	addRoom := func(deviceID string, room Room) {
+		if rooms := s.roomsByDeviceID[deviceID]; !slices.Contains(rooms, room) {
+			s.roomsByDeviceID[deviceID] = append(rooms, room)
+		}
+	}
+	for _, group := range s.groups {
+		members := group.GetMembers()
+		for _, member := range members {
+			addGroup(member.GetDevice().GetID(), group)
+		}
+		for _, deviceID := range deviceIDsByGroupID[group.GetID()] {
+			addGroup(deviceID, group)
+		}
+		if metaGroup, ok := group.(MetaGroup); ok {
+			room := &room{
+				metaGroup: metaGroup,
+				state:     s,
+			}
+			s.rooms = append(s.rooms, room)
+			for _, member := range members {
+				addRoom(member.GetDevice().GetID(), room)
+			}
+		}
+	}
+	s.clientsByID = make(map[string]Client, len(s.clients))
+	for _, client := range s.clients {
+		s.clientsByID[client.GetID()] = client
+	}
+}
+
+func (s state) ApplyEvents(events ...Event) State {
+	next := state{
+		home:     s.home,
+		devices:  slices.Clone(s.devices),
+		groups:   slices.Clone(s.groups),
+		clients:  s.clients,
+		extras:   s.extras,
+		warnings: s.warnings,
+	}
+	for _, event := range events {
+		switch changedEvent := event.(type) {
+		case DeviceChangedEvent:
+			if device := changedEvent.GetDevice(); device != nil {
+				next.devices = replaceByID(next.devices, device)
+			}
+		case GroupChangedEvent:
+			if group := changedEvent.GetGroup(); group != nil {
+				next.groups = replaceByID(next.groups, group)
+			}
+		case HomeChangedEvent:
+			if changedHome, ok := changedEvent.GetHome().(*home); ok {
+				next.home = changedHome
+			}
+		}
+	}
+	// Groups are bound to the state resolving their members, so they are decoded
+	// again from their raw JSON instead of rebinding the groups of this state
+	d := newDecoder(DecodeOptions{})
+	for i, group := range next.groups {
+		if rebound, _ := d.decodeGroupValue(group.GetRaw()); rebound != nil {
+			next.groups[i] = rebound
+		}
+	}
+	sortByName(next.devices)
+	sortByName(next.groups)
+	next.index()
+	return &next
+}
+
+// replaceByID replaces the entity with the same ID or appends the entity.
+func replaceByID[T identifiable](entities []T, entity T) []T {
+	for i, existing := range entities {
+		if existing.GetID() == entity.GetID() {
+			entities[i] = entity
+			return entities
+		}
+	}
+	return append(entities, entity)
+}
+
+// stateBound is implemented by all groups to set the reference
+// to the state used for resolving their members.
+type stateBound interface {
+	setState(state *state)
+}
+
+// ReadState reads and decodes a state in the wire format of the HomematicIP Cloud.
+func ReadState(reader io.Reader) (State, error) {
+	return ReadStateWithOptions(reader, DecodeOptions{})
+}
+
+// ReadStateWithOptions reads and decodes a state like ReadState using the given options.
+func ReadStateWithOptions(reader io.Reader, options DecodeOptions) (State, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	return parseState(data, options)
+}
+
+// MarshalJSON marshals the state back into the wire format of the HomematicIP Cloud
+// with devices, groups and clients as maps by ID. All entities are marshalled by their
+// raw JSON, so entities of registered types need not implement MarshalJSON.
+func (s state) MarshalJSON() ([]byte, error) {
+	attributes := make(map[string]any, len(s.extras)+4)
+	for key, value := range s.extras {
+		attributes[key] = value
+	}
+	if s.home != nil {
+		attributes["home"] = s.home.GetRaw()
+	}
+	attributes["devices"] = rawByID(s.devices)
+	attributes["groups"] = rawByID(s.groups)
+	attributes["clients"] = rawByID(s.clients)
+	return json.Marshal(attributes)
+}
+
+func rawByID[T rawIdentifiable](entities []T) map[string]json.RawMessage {
+	values := make(map[string]json.RawMessage, len(entities))
+	for _, entity := range entities {
+		values[entity.GetID()] = entity.GetRaw()
+	}
+	return values
+}
+
+func (s state) GetHome() Home {
+	if s.home == nil {
+		return nil
+	}
+	return s.home
+}
+
+func (s state) GetWeather() Weather {
+	if s.home == nil {
+		return nil
+	}
+	return s.home.GetWeather()
+}
+
+func (s state) GetLocation() Location {
+	if s.home == nil {
+		return nil
+	}
+	return s.home.GetLocation()
 }
 
 func (s state) GetDevices() Devices {
-	return s.Devices
+	return s.devices
 }
 
 func (s state) GetGroups() Groups {
-	return s.Groups
+	return s.groups
 }
 
 func (s state) GetClients() Clients {
-	return s.Clients
+	return s.clients
+}
+
+func (s state) GetDecodeWarnings() []DecodeWarning {
+	return s.warnings
 }
 
 func (s state) GetDevicesByType(deviceType string) Devices {
 	var devices Devices
-	for _, device := range s.Devices {
+	for _, device := range s.devices {
 		if device.GetType() == deviceType {
 			devices = append(devices, device)
 		}
@@ -30,7 +278,7 @@ func (s state) GetDevicesByType(deviceType string) Devices {
 
 func (s state) GetGroupsByType(groupType string) Groups {
 	var groups Groups
-	for _, group := range s.Groups {
+	for _, group := range s.groups {
 		if group.GetType() == groupType {
 			groups = append(groups, group)
 		}
@@ -39,21 +287,41 @@ func (s state) GetGroupsByType(groupType string) Groups {
 }
 
 func (s state) GetDeviceByID(deviceID string) Device {
-	for _, device := range s.Devices {
-		if device.GetID() == deviceID {
-			return device
-		}
-	}
-	return nil
+	return s.devicesByID[deviceID]
 }
 
 func (s state) GetGroupByID(groupID string) Group {
-	for _, group := range s.Groups {
-		if group.GetID() == groupID {
-			return group
+	return s.groupsByID[groupID]
+}
+
+func (s state) GetClientByID(clientID string) Client {
+	return s.clientsByID[clientID]
+}
+
+func (s state) GetGroupsOfDevice(deviceID string) Groups {
+	return s.groupsByDeviceID[deviceID]
+}
+
+func (s state) Devices() DeviceQuery {
+	return NewDeviceQuery(s, s.devices)
+}
+
+func (s state) GetRooms() Rooms {
+	return s.rooms
+}
+
+func (s state) GetRoomsOfDevice(deviceID string) Rooms {
+	return s.roomsByDeviceID[deviceID]
+}
+
+func (s state) GetRoomOfDevice(deviceID string) Room {
+	var first Room
+	for _, room := range s.roomsByDeviceID[deviceID] {
+		if first == nil || room.GetID() < first.GetID() {
+			first = room
 		}
 	}
-	return nil
+	return first
 }
 
 func (s state) GetFunctionalChannelsByType(deviceType, channelType string) FunctionalChannels {
@@ -65,3 +333,26 @@ func (s state) GetFunctionalChannelsByType(deviceType, channelType string) Funct
 	}
 	return channels
 }
+
+// ======================================================
+
+type identifiable interface {
+	Named
+	GetID() string
+}
+
+type rawIdentifiable interface {
+	identifiable
+	Raw
+}
+
+// sortByName sorts by name and then by ID, so the order does not
+// depend on the iteration order of the decoded maps.
+func sortByName[T identifiable](values []T) {
+	slices.SortStableFunc(values, func(a, b T) int {
+		if result := cmp.Compare(a.GetName(), b.GetName()); result != 0 {
+			return result
+		}
+		return cmp.Compare(a.GetID(), b.GetID())
+	})
+}
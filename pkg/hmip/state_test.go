@@ -0,0 +1,101 @@
+package hmip
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func readFixture(t testing.TB, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatalf("reading fixture %s: %v", name, err)
+	}
+	return data
+}
+
+// assertSameJSON compares two JSON documents ignoring formatting and the order of members.
+func assertSameJSON(t *testing.T, expected, actual []byte) {
+	t.Helper()
+	var expectedValue, actualValue any
+	if err := json.Unmarshal(expected, &expectedValue); err != nil {
+		t.Fatalf("invalid expected JSON: %v", err)
+	}
+	if err := json.Unmarshal(actual, &actualValue); err != nil {
+		t.Fatalf("invalid actual JSON: %v", err)
+	}
+	if !reflect.DeepEqual(expectedValue, actualValue) {
+		t.Errorf("JSON differs\nexpected: %s\nactual:   %s", expected, actual)
+	}
+}
+
+func TestStateRoundTrip(t *testing.T) {
+	data := readFixture(t, "state.json")
+	state, err := ParseState(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	marshalled, err := json.Marshal(state)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	assertSameJSON(t, data, marshalled)
+}
+
+// floorTerminalBlockChannel and floorTerminalBlock are registered like types of other packages,
+// embedding the interfaces instead of the unexported base types.
+type floorTerminalBlockChannel struct {
+	FunctionalChannel          `json:"-"`
+	FrostProtectionTemperature float64 `json:"frostProtectionTemperature"`
+}
+
+type floorTerminalBlock struct {
+	Device `json:"-"`
+}
+
+func TestStateRoundTripRegisteredTypes(t *testing.T) {
+	RegisterChannelType("FLOOR_TERMINAL_BLOCK_CHANNEL", func(base FunctionalChannel) FunctionalChannel {
+		return &floorTerminalBlockChannel{FunctionalChannel: base}
+	})
+	RegisterDeviceType("FLOOR_TERMINAL_BLOCK_12", func(base Device) Device {
+		return &floorTerminalBlock{Device: base}
+	})
+	defer unregisterTypes("FLOOR_TERMINAL_BLOCK_CHANNEL", "FLOOR_TERMINAL_BLOCK_12")
+
+	data := readFixture(t, "state.json")
+	state, err := ParseState(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	device, ok := state.GetDeviceByID("3014F711A00008FBE9B15A0F").(*floorTerminalBlock)
+	if !ok {
+		t.Fatal("expected the registered device type")
+	}
+	channel, ok := device.GetChannel(1).(*floorTerminalBlockChannel)
+	if !ok || channel.FrostProtectionTemperature != 8 {
+		t.Fatal("expected the registered channel type")
+	}
+	if channel.GetDevice() != Device(device) {
+		t.Error("expected the channel to refer to the registered device")
+	}
+
+	marshalled, err := json.Marshal(state)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	assertSameJSON(t, data, marshalled)
+}
+
+func unregisterTypes(types ...string) {
+	registryLock.Lock()
+	defer registryLock.Unlock()
+	for _, name := range types {
+		delete(channelFactories, name)
+		delete(deviceFactories, name)
+	}
+}
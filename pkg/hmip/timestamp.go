@@ -9,12 +9,15 @@ type HomematicTimestamp struct {
 	time.Time
 }
 
-func (t *HomematicTimestamp) MarshalJSON() ([]byte, error) {
+func (t HomematicTimestamp) MarshalJSON() ([]byte, error) {
 	s := strconv.Itoa(int(t.Time.UnixMilli()))
 	return []byte(s), nil
 }
 
 func (t *HomematicTimestamp) UnmarshalJSON(value []byte) error {
+	if string(value) == "null" {
+		return nil // Keeping the zero time like the standard library does for null values
+	}
 	unix, err := strconv.Atoi(string(value))
 	if err != nil {
 		return err
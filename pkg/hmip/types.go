@@ -1,6 +1,9 @@
 package hmip
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"io"
 	"time"
 )
@@ -10,33 +13,169 @@ const (
 	EVENT_TYPE_GROUP_CHANGED  = "GROUP_CHANGED"
 	EVENT_TYPE_HOME_CHANGED   = "HOME_CHANGED"
 
-	CHANNEL_TYPE_DEVICE_BASE       = "DEVICE_BASE"
-	CHANNEL_TYPE_SWITCH            = "SWITCH_CHANNEL"
-	CHANNEL_TYPE_SWITCH_MEASURING  = "SWITCH_MEASURING_CHANNEL"
-	CHANNEL_TYPE_CLIMATE_SENSOR    = "CLIMATE_SENSOR_CHANNEL"
-	CHANNEL_TYPE_ACCESS_CONTROLLER = "ACCESS_CONTROLLER_CHANNEL"
-	CHANNEL_TYPE_SMOKE_DETECTOR    = "SMOKE_DETECTOR_CHANNEL"
-
-	DEVICE_TYPE_TEMPERATURE_HUMIDITY_SENSOR_OUTDOOR = "TEMPERATURE_HUMIDITY_SENSOR_OUTDOOR"
-	DEVICE_TYPE_PLUGABLE_SWITCH                     = "PLUGABLE_SWITCH"
-	DEVICE_TYPE_PLUGABLE_SWITCH_MEASURING           = "PLUGABLE_SWITCH_MEASURING"
-	DEVICE_TYPE_HOME_CONTROL_ACCESS_POINT           = "HOME_CONTROL_ACCESS_POINT"
-	DEVICE_TYPE_SMOKE_DETECTOR                      = "SMOKE_DETECTOR"
+	CHANNEL_TYPE_DEVICE_BASE                             = "DEVICE_BASE"
+	CHANNEL_TYPE_SWITCH                                  = "SWITCH_CHANNEL"
+	CHANNEL_TYPE_SWITCH_MEASURING                        = "SWITCH_MEASURING_CHANNEL"
+	CHANNEL_TYPE_CLIMATE_SENSOR                          = "CLIMATE_SENSOR_CHANNEL"
+	CHANNEL_TYPE_ACCESS_CONTROLLER                       = "ACCESS_CONTROLLER_CHANNEL"
+	CHANNEL_TYPE_SMOKE_DETECTOR                          = "SMOKE_DETECTOR_CHANNEL"
+	CHANNEL_TYPE_HEATING_THERMOSTAT                      = "HEATING_THERMOSTAT_CHANNEL"
+	CHANNEL_TYPE_WALL_MOUNTED_THERMOSTAT_PRO             = "WALL_MOUNTED_THERMOSTAT_PRO_CHANNEL"
+	CHANNEL_TYPE_WALL_MOUNTED_THERMOSTAT_WITHOUT_DISPLAY = "WALL_MOUNTED_THERMOSTAT_WITHOUT_DISPLAY_CHANNEL"
+	CHANNEL_TYPE_SHUTTER                                 = "SHUTTER_CHANNEL"
+	CHANNEL_TYPE_BLIND                                   = "BLIND_CHANNEL"
+	CHANNEL_TYPE_DIMMER                                  = "DIMMER_CHANNEL"
+	CHANNEL_TYPE_NOTIFICATION_LIGHT                      = "NOTIFICATION_LIGHT_CHANNEL"
+	CHANNEL_TYPE_SHUTTER_CONTACT                         = "SHUTTER_CONTACT_CHANNEL"
+	CHANNEL_TYPE_ROTARY_HANDLE                           = "ROTARY_HANDLE_CHANNEL"
+	CHANNEL_TYPE_CONTACT_INTERFACE                       = "CONTACT_INTERFACE_CHANNEL"
+	CHANNEL_TYPE_MOTION_DETECTION                        = "MOTION_DETECTION_CHANNEL"
+	CHANNEL_TYPE_PRESENCE_DETECTION                      = "PRESENCE_DETECTION_CHANNEL"
+
+	DEVICE_TYPE_TEMPERATURE_HUMIDITY_SENSOR_OUTDOOR    = "TEMPERATURE_HUMIDITY_SENSOR_OUTDOOR"
+	DEVICE_TYPE_PLUGABLE_SWITCH                        = "PLUGABLE_SWITCH"
+	DEVICE_TYPE_PLUGABLE_SWITCH_MEASURING              = "PLUGABLE_SWITCH_MEASURING"
+	DEVICE_TYPE_HOME_CONTROL_ACCESS_POINT              = "HOME_CONTROL_ACCESS_POINT"
+	DEVICE_TYPE_SMOKE_DETECTOR                         = "SMOKE_DETECTOR"
+	DEVICE_TYPE_HEATING_THERMOSTAT                     = "HEATING_THERMOSTAT"
+	DEVICE_TYPE_HEATING_THERMOSTAT_COMPACT             = "HEATING_THERMOSTAT_COMPACT"
+	DEVICE_TYPE_HEATING_THERMOSTAT_COMPACT_PLUS        = "HEATING_THERMOSTAT_COMPACT_PLUS"
+	DEVICE_TYPE_HEATING_THERMOSTAT_EVO                 = "HEATING_THERMOSTAT_EVO"
+	DEVICE_TYPE_HEATING_THERMOSTAT_THREE               = "HEATING_THERMOSTAT_THREE"
+	DEVICE_TYPE_HEATING_THERMOSTAT_FLEX                = "HEATING_THERMOSTAT_FLEX"
+	DEVICE_TYPE_WALL_MOUNTED_THERMOSTAT_PRO            = "WALL_MOUNTED_THERMOSTAT_PRO"
+	DEVICE_TYPE_WALL_MOUNTED_THERMOSTAT_BASIC_HUMIDITY = "WALL_MOUNTED_THERMOSTAT_BASIC_HUMIDITY"
+	DEVICE_TYPE_BRAND_WALL_MOUNTED_THERMOSTAT          = "BRAND_WALL_MOUNTED_THERMOSTAT"
+	DEVICE_TYPE_TEMPERATURE_HUMIDITY_SENSOR            = "TEMPERATURE_HUMIDITY_SENSOR"
+	DEVICE_TYPE_TEMPERATURE_HUMIDITY_SENSOR_DISPLAY    = "TEMPERATURE_HUMIDITY_SENSOR_DISPLAY"
+	DEVICE_TYPE_BRAND_SHUTTER                          = "BRAND_SHUTTER"
+	DEVICE_TYPE_FULL_FLUSH_SHUTTER                     = "FULL_FLUSH_SHUTTER"
+	DEVICE_TYPE_BRAND_BLIND                            = "BRAND_BLIND"
+	DEVICE_TYPE_FULL_FLUSH_BLIND                       = "FULL_FLUSH_BLIND"
+	DEVICE_TYPE_DIN_RAIL_BLIND_4                       = "DIN_RAIL_BLIND_4"
 
 	CONNECTION_TYPE_RF  = "HMIP_RF"
 	CONNECTION_TYPE_LAN = "HMIP_LAN"
 
-	GROUP_TYPE_META        = "META"
-	GROUP_TYPE_ENVIRONMENT = "ENVIRONMENT"
+	GROUP_TYPE_META          = "META"
+	GROUP_TYPE_ENVIRONMENT   = "ENVIRONMENT"
+	GROUP_TYPE_HEATING       = "HEATING"
+	GROUP_TYPE_SWITCHING     = "SWITCHING"
+	GROUP_TYPE_SECURITY      = "SECURITY"
+	GROUP_TYPE_SECURITY_ZONE = "SECURITY_ZONE"
+
+	VALVE_STATE_STATE_NOT_AVAILABLE  = "STATE_NOT_AVAILABLE"
+	VALVE_STATE_RUN_TO_START         = "RUN_TO_START"
+	VALVE_STATE_WAIT_FOR_ADAPTION    = "WAIT_FOR_ADAPTION"
+	VALVE_STATE_ADAPTION_IN_PROGRESS = "ADAPTION_IN_PROGRESS"
+	VALVE_STATE_ADAPTION_DONE        = "ADAPTION_DONE"
+	VALVE_STATE_TOO_TIGHT            = "TOO_TIGHT"
+	VALVE_STATE_ADJUSTMENT_TOO_BIG   = "ADJUSTMENT_TOO_BIG"
+	VALVE_STATE_ADJUSTMENT_TOO_SMALL = "ADJUSTMENT_TOO_SMALL"
+	VALVE_STATE_ERROR_POSITION       = "ERROR_POSITION"
+
+	CLIMATE_CONTROL_DISPLAY_ACTUAL          = "ACTUAL"
+	CLIMATE_CONTROL_DISPLAY_SETPOINT        = "SETPOINT"
+	CLIMATE_CONTROL_DISPLAY_ACTUAL_HUMIDITY = "ACTUAL_HUMIDITY"
+
+	RGB_COLOR_STATE_BLACK     = "BLACK"
+	RGB_COLOR_STATE_BLUE      = "BLUE"
+	RGB_COLOR_STATE_GREEN     = "GREEN"
+	RGB_COLOR_STATE_TURQUOISE = "TURQUOISE"
+	RGB_COLOR_STATE_RED       = "RED"
+	RGB_COLOR_STATE_PURPLE    = "PURPLE"
+	RGB_COLOR_STATE_YELLOW    = "YELLOW"
+	RGB_COLOR_STATE_WHITE     = "WHITE"
+
+	MOTION_DETECTION_SEND_INTERVAL_SECONDS_30  = "SECONDS_30"
+	MOTION_DETECTION_SEND_INTERVAL_SECONDS_60  = "SECONDS_60"
+	MOTION_DETECTION_SEND_INTERVAL_SECONDS_120 = "SECONDS_120"
+	MOTION_DETECTION_SEND_INTERVAL_SECONDS_240 = "SECONDS_240"
+	MOTION_DETECTION_SEND_INTERVAL_SECONDS_480 = "SECONDS_480"
+
+	WINDOW_STATE_OPEN   = "OPEN"
+	WINDOW_STATE_CLOSED = "CLOSED"
+	WINDOW_STATE_TILTED = "TILTED"
 
 	ORIGIN_TYPE_DEVICE = "DEVICE"
+
+	WEATHER_CONDITION_CLEAR                                = "CLEAR"
+	WEATHER_CONDITION_LIGHT_CLOUDY                         = "LIGHT_CLOUDY"
+	WEATHER_CONDITION_CLOUDY                               = "CLOUDY"
+	WEATHER_CONDITION_CLOUDY_WITH_RAIN                     = "CLOUDY_WITH_RAIN"
+	WEATHER_CONDITION_CLOUDY_WITH_SNOW_RAIN                = "CLOUDY_WITH_SNOW_RAIN"
+	WEATHER_CONDITION_HEAVILY_CLOUDY                       = "HEAVILY_CLOUDY"
+	WEATHER_CONDITION_HEAVILY_CLOUDY_WITH_RAIN             = "HEAVILY_CLOUDY_WITH_RAIN"
+	WEATHER_CONDITION_HEAVILY_CLOUDY_WITH_STRONG_RAIN      = "HEAVILY_CLOUDY_WITH_STRONG_RAIN"
+	WEATHER_CONDITION_HEAVILY_CLOUDY_WITH_SNOW             = "HEAVILY_CLOUDY_WITH_SNOW"
+	WEATHER_CONDITION_HEAVILY_CLOUDY_WITH_SNOW_RAIN        = "HEAVILY_CLOUDY_WITH_SNOW_RAIN"
+	WEATHER_CONDITION_HEAVILY_CLOUDY_WITH_THUNDER          = "HEAVILY_CLOUDY_WITH_THUNDER"
+	WEATHER_CONDITION_HEAVILY_CLOUDY_WITH_RAIN_AND_THUNDER = "HEAVILY_CLOUDY_WITH_RAIN_AND_THUNDER"
+	WEATHER_CONDITION_FOGGY                                = "FOGGY"
+	WEATHER_CONDITION_STRONG_WIND                          = "STRONG_WIND"
+	WEATHER_CONDITION_UNKNOWN                              = "UNKNOWN"
+
+	WEATHER_DAY_TIME_DAY      = "DAY"
+	WEATHER_DAY_TIME_TWILIGHT = "TWILIGHT"
+	WEATHER_DAY_TIME_NIGHT    = "NIGHT"
+
+	FUNCTIONAL_HOME_TYPE_INDOOR_CLIMATE          = "INDOOR_CLIMATE"
+	FUNCTIONAL_HOME_TYPE_SECURITY_AND_ALARM      = "SECURITY_AND_ALARM"
+	FUNCTIONAL_HOME_TYPE_LIGHT_AND_SHADOW        = "LIGHT_AND_SHADOW"
+	FUNCTIONAL_HOME_TYPE_WEATHER_AND_ENVIRONMENT = "WEATHER_AND_ENVIRONMENT"
+	FUNCTIONAL_HOME_TYPE_ENERGY                  = "ENERGY"
+
+	ABSENCE_TYPE_NOT_ABSENT = "NOT_ABSENT"
+	ABSENCE_TYPE_PERIOD     = "PERIOD"
+	ABSENCE_TYPE_PERMANENT  = "PERMANENT"
+	ABSENCE_TYPE_VACATION   = "VACATION"
+	ABSENCE_TYPE_PARTY      = "PARTY"
+
+	SECURITY_ZONE_ACTIVATION_MODE_ACTIVATION_WITH_DEVICE_IGNORELIST = "ACTIVATION_WITH_DEVICE_IGNORELIST"
+	SECURITY_ZONE_ACTIVATION_MODE_ACTIVATION_IF_ALL_IN_VALID_STATE  = "ACTIVATION_IF_ALL_IN_VALID_STATE"
+
+	DEVICE_UPDATE_STATE_UP_TO_DATE                      = "UP_TO_DATE"
+	DEVICE_UPDATE_STATE_TRANSFERING_UPDATE              = "TRANSFERING_UPDATE"
+	DEVICE_UPDATE_STATE_UPDATE_AVAILABLE                = "UPDATE_AVAILABLE"
+	DEVICE_UPDATE_STATE_UPDATE_AUTHORIZED               = "UPDATE_AUTHORIZED"
+	DEVICE_UPDATE_STATE_BACKGROUND_UPDATE_NOT_SUPPORTED = "BACKGROUND_UPDATE_NOT_SUPPORTED"
+
+	LIVE_UPDATE_STATE_UP_TO_DATE                = "UP_TO_DATE"
+	LIVE_UPDATE_STATE_UPDATE_AVAILABLE          = "UPDATE_AVAILABLE"
+	LIVE_UPDATE_STATE_UPDATE_INCOMPLETE         = "UPDATE_INCOMPLETE"
+	LIVE_UPDATE_STATE_LIVE_UPDATE_NOT_SUPPORTED = "LIVE_UPDATE_NOT_SUPPORTED"
+
+	HOME_UPDATE_STATE_UP_TO_DATE          = "UP_TO_DATE"
+	HOME_UPDATE_STATE_UPDATE_AVAILABLE    = "UPDATE_AVAILABLE"
+	HOME_UPDATE_STATE_PERFORM_UPDATE_SENT = "PERFORM_UPDATE_SENT"
+	HOME_UPDATE_STATE_PERFORMING_UPDATE   = "PERFORMING_UPDATE"
+
+	MODEL_FAMILY_UNKNOWN      = "UNKNOWN"
+	MODEL_FAMILY_EQ3          = "EQ3"
+	MODEL_FAMILY_HOMEMATIC_IP = "HMIP"
+	MODEL_FAMILY_ACCESS_POINT = "ACCESS_POINT"
+
+	REGISTRATION_STEP_LOOKUP             = "LOOKUP"
+	REGISTRATION_STEP_CONNECTION_REQUEST = "CONNECTION_REQUEST"
+	REGISTRATION_STEP_ACKNOWLEDGE        = "ACKNOWLEDGE"
+	REGISTRATION_STEP_AUTH_TOKEN         = "AUTH_TOKEN"
+	REGISTRATION_STEP_CONFIRM_AUTH_TOKEN = "CONFIRM_AUTH_TOKEN"
+
+	CHANGE_TYPE_ADDED   = "ADDED"
+	CHANGE_TYPE_REMOVED = "REMOVED"
+	CHANGE_TYPE_CHANGED = "CHANGED"
 )
 
+// ErrClientRevoked is returned when the HomematicIP Cloud rejects the credentials
+// of the client, e.g. because the client was removed in the HmIP app.
+var ErrClientRevoked = errors.New("client has been revoked")
+
 // ======================================================
 
 // Homematic is the base interface to access the HomemticIP Cloud.
 type Homematic interface {
 	LoadCurrentState() (State, error)
+	VerifyCredentials(ctx context.Context) error
 	RegisterEventHandler(handler EventHandler, eventTypes ...string)
 	SetEventLog(writer io.Writer)
 	ListenForEvents() error
@@ -47,8 +186,12 @@ type Homematic interface {
 // ======================================================
 
 // State represents the current state of the HomematicIP Cloud
-// with all devices, groups and clients.
+// with the home and all devices, groups and clients. Devices, groups
+// and clients are sorted by name and ID.
 type State interface {
+	GetHome() Home
+	GetWeather() Weather
+	GetLocation() Location
 	GetDevices() Devices
 	GetGroups() Groups
 	GetClients() Clients
@@ -56,7 +199,146 @@ type State interface {
 	GetGroupsByType(groupType string) Groups
 	GetDeviceByID(deviceID string) Device
 	GetGroupByID(groupID string) Group
+	GetClientByID(clientID string) Client
+	GetGroupsOfDevice(deviceID string) Groups
+	GetRooms() Rooms
+	// GetRoomsOfDevice returns all rooms containing the device, ordered like GetRooms
+	GetRoomsOfDevice(deviceID string) Rooms
+	// GetRoomOfDevice returns the room of the device. If the device is contained in
+	// several rooms, the room with the lowest ID of its META group is returned.
+	GetRoomOfDevice(deviceID string) Room
 	GetFunctionalChannelsByType(deviceType, channelType string) FunctionalChannels
+	Devices() DeviceQuery
+	GetDecodeWarnings() []DecodeWarning
+	// ApplyEvents returns a new state with the devices, groups and home of the events
+	// replacing the ones with the same ID, so queries can run on the live state
+	// of the event handler. The state itself is not changed.
+	ApplyEvents(events ...Event) State
+}
+
+// DeviceQuery filters devices of a State with predicates, e.g.
+// state.Devices().Where(InRoom("Kitchen"), LowBattery()).Channels(CHANNEL_TYPE_SWITCH).
+// Queries are immutable, each method returns a new query.
+type DeviceQuery interface {
+	Where(predicates ...DevicePredicate) DeviceQuery
+	Channels(channelTypes ...string) ChannelQuery
+	All() Devices
+	First() Device
+	Count() int
+}
+
+// ChannelQuery filters functional channels of a State with predicates.
+// Queries are immutable, each method returns a new query.
+type ChannelQuery interface {
+	Where(predicates ...ChannelPredicate) ChannelQuery
+	Devices() DeviceQuery
+	All() FunctionalChannels
+	First() FunctionalChannel
+	Count() int
+}
+
+// ======================================================
+
+// Home represents the current state of the home with
+// information about the access point and the radio traffic.
+type Home interface {
+	Raw
+	GetID() string
+	GetCurrentAPVersion() string
+	GetAvailableAPVersion() string
+	IsConnected() bool
+	GetDutyCycle() float64
+	GetCarrierSense() float64
+	GetTimeZoneID() string
+	IsPINAssigned() bool
+	GetUpdateState() string
+	GetPowerMeterUnitPrice() float64
+	GetPowerMeterCurrency() string
+	GetWeather() Weather
+	GetLocation() Location
+	GetFunctionalHomes() FunctionalHomes
+	GetFunctionalHome(functionalHomeType string) FunctionalHome
+}
+
+// Weather represents the current weather at the location of the home.
+type Weather interface {
+	GetTemperature() float64
+	GetMinTemperature() float64
+	GetMaxTemperature() float64
+	GetHumidity() int
+	GetVapourAmount() float64
+	GetWindSpeed() float64
+	GetWindDirection() int
+	GetWeatherCondition() string
+	GetWeatherDayTime() string
+}
+
+// Location represents the location of the home.
+type Location interface {
+	GetCity() string
+	GetLatitude() float64
+	GetLongitude() float64
+}
+
+// ======================================================
+
+// FunctionalHome represents the configuration of a solution of the home
+// like indoor climate or security. It is extended in special sub interfaces.
+type FunctionalHome interface {
+	Typed
+	IsActive() bool
+	GetFunctionalGroups() []string
+}
+type FunctionalHomes []FunctionalHome
+
+// IndoorClimateHome is a special functional home for type FUNCTIONAL_HOME_TYPE_INDOOR_CLIMATE
+// containing the eco mode and absence configuration of the heating.
+type IndoorClimateHome interface {
+	FunctionalHome
+	GetEcoTemperature() float64
+	GetEcoDuration() string
+	GetAbsenceType() string
+	// GetAbsenceEndTime returns the end of the absence in the time zone of the home
+	GetAbsenceEndTime() time.Time
+	IsOptimumStartStopEnabled() bool
+}
+
+// SecurityAndAlarmHome is a special functional home for type FUNCTIONAL_HOME_TYPE_SECURITY_AND_ALARM
+// containing the activation and alarm state of the security system.
+type SecurityAndAlarmHome interface {
+	FunctionalHome
+	IsActivationInProgress() bool
+	IsAlarmActive() bool
+	GetAlarmEventTime() time.Time
+	GetAlarmEventDeviceID() string
+	HasIntrusionAlertThroughSmokeDetectors() bool
+	GetZoneActivationDelay() float64
+	GetSecurityZoneActivationMode() string
+	GetSecurityZones() map[string]string
+}
+
+// LightAndShadowHome is a special functional home for type FUNCTIONAL_HOME_TYPE_LIGHT_AND_SHADOW
+// containing the groups used for shutter and switching profiles.
+type LightAndShadowHome interface {
+	FunctionalHome
+	GetExtendedLinkedShutterGroups() []string
+	GetExtendedLinkedSwitchingGroups() []string
+	GetShutterProfileGroups() []string
+	GetSwitchingProfileGroups() []string
+}
+
+// WeatherAndEnvironmentHome is a special functional home for type FUNCTIONAL_HOME_TYPE_WEATHER_AND_ENVIRONMENT.
+// It contains only the common functional home data.
+type WeatherAndEnvironmentHome interface {
+	FunctionalHome
+	isWeatherAndEnvironmentHome()
+}
+
+// EnergyHome is a special functional home for type FUNCTIONAL_HOME_TYPE_ENERGY.
+// It contains only the common functional home data.
+type EnergyHome interface {
+	FunctionalHome
+	isEnergyHome()
 }
 
 // ======================================================
@@ -64,14 +346,26 @@ type State interface {
 // Device represents the current state of a device.
 // Specific information is stored in the functional channels.
 type Device interface {
+	Raw
 	Stateful
 	Named
 	Typed
 	GetModel() string
-	GetSGTIN() string
+	GetSGTIN() SGTIN
 	IsPermanentlyReachable() bool
 	GetConnectionType() string
+	GetFirmwareVersion() string
+	GetFirmwareVersionInteger() int
+	GetAvailableFirmwareVersion() string
+	GetUpdateState() string
+	GetLiveUpdateState() string
+	IsUpdateAvailable() bool
+	GetManufacturerCode() int
+	GetOEM() string
+	GetMeasuredAttributes() map[string]map[string]any
+	IsAutomaticValveAdaptionNeeded() bool
 	GetFunctionalChannels() FunctionalChannels
+	GetChannel(index int) FunctionalChannel
 	GetFunctionalChannelsByType(channelType string) FunctionalChannels
 }
 type Devices []Device
@@ -79,23 +373,61 @@ type Devices []Device
 // ======================================================
 
 // Group represents the current state of a group.
+// The members are only resolved for groups being part of a State,
+// for groups received with events GetMembers returns nil.
 type Group interface {
+	Raw
 	Stateful
 	Named
 	Typed
+	GetMembers() GroupMembers
 }
 type Groups []Group
 
+// GroupMember represents a functional channel of a device being member of a group.
+type GroupMember interface {
+	GetDevice() Device
+	GetChannel() FunctionalChannel
+}
+type GroupMembers []GroupMember
+
 // MetaGroup represents the current state of a group with GROUP_TYPE_META.
+// Meta groups are shown as rooms in the HmIP app.
 type MetaGroup interface {
 	Group
 	GetIcon() string
+	GetGroupIDs() []string
 }
 
 // ======================================================
 
+// Room represents a room of the home based on a MetaGroup with the devices
+// in the room, its sub groups and readings aggregated over the channels
+// being members of the MetaGroup.
+// GetActualTemperature and GetHumidity return the average of all climate
+// measuring channels (or of the heating groups, if there are none) and
+// false, if there is no reading in the room.
+type Room interface {
+	Named
+	GetID() string
+	GetIcon() string
+	GetMetaGroup() MetaGroup
+	GetDevices() Devices
+	GetSubGroups() Groups
+	GetHeatingGroups() Groups
+	GetSecurityGroups() Groups
+	GetActualTemperature() (float64, bool)
+	GetHumidity() (int, bool)
+	GetOpenWindows() FunctionalChannels
+	GetActiveSwitches() FunctionalChannels
+}
+type Rooms []Room
+
+// ======================================================
+
 // Client represents a registered client.
 type Client interface {
+	Raw
 	Typed
 	Named
 	GetID() string
@@ -108,8 +440,15 @@ type Clients []Client
 
 // FunctionalChannel represents a functional channel as
 // part of a device. It is extended in special sub interfaces.
+// The channel is identified by the ID of its device and its index.
 type FunctionalChannel interface {
+	Raw
 	Typed
+	GetIndex() int
+	GetLabel() string
+	GetDeviceID() string
+	GetGroupIDs() []string
+	GetDevice() Device
 }
 type FunctionalChannels []FunctionalChannel
 
@@ -130,6 +469,7 @@ type BaseDeviceChannel interface {
 type SwitchChannel interface {
 	FunctionalChannel
 	Switchable
+	isSwitchChannel()
 }
 
 // SwitchMeasuringChannel is a special functional channel for type CHANNEL_TYPE_SWITCH_MEASURING
@@ -145,6 +485,7 @@ type SwitchMeasuringChannel interface {
 type ClimateSensorChannel interface {
 	FunctionalChannel
 	ClimateMeasuring
+	isClimateSensorChannel()
 }
 
 // SmokeDetectorChannel is a special functional channel for type CHANNEL_TYPE_SMOKE_DETECTOR
@@ -154,6 +495,107 @@ type SmokeDetectorChannel interface {
 	IsChamberDegraded() bool
 }
 
+// HeatingThermostatChannel is a special functional channel for type CHANNEL_TYPE_HEATING_THERMOSTAT
+// containing the valve and the temperatures of radiator thermostats.
+type HeatingThermostatChannel interface {
+	FunctionalChannel
+	// GetValvePosition returns the opening of the valve between 0.0 (closed) and 1.0 (open)
+	GetValvePosition() float64
+	// GetValveState returns one of the VALVE_STATE constants
+	GetValveState() string
+	GetSetPointTemperature() float64
+	GetTemperatureOffset() float64
+	GetValveActualTemperature() float64
+}
+
+// WallMountedThermostatChannel is a special functional channel for type CHANNEL_TYPE_WALL_MOUNTED_THERMOSTAT_WITHOUT_DISPLAY
+// containing the measuring data and the set point of wall-mounted thermostats.
+type WallMountedThermostatChannel interface {
+	FunctionalChannel
+	ClimateMeasuring
+	GetSetPointTemperature() float64
+	GetTemperatureOffset() float64
+}
+
+// WallMountedThermostatProChannel is a special functional channel for type CHANNEL_TYPE_WALL_MOUNTED_THERMOSTAT_PRO
+// containing additionally the value shown on the display of wall-mounted thermostats.
+type WallMountedThermostatProChannel interface {
+	WallMountedThermostatChannel
+	// GetDisplay returns one of the CLIMATE_CONTROL_DISPLAY constants
+	GetDisplay() string
+}
+
+// ShutterChannel is a special functional channel for type CHANNEL_TYPE_SHUTTER
+// containing the level and the calibration of shutter actuators.
+type ShutterChannel interface {
+	FunctionalChannel
+	ShutterLevelled
+	// GetTopToBottomReferenceTime returns the time in seconds the shutter needs to close completely
+	GetTopToBottomReferenceTime() float64
+	// GetBottomToTopReferenceTime returns the time in seconds the shutter needs to open completely
+	GetBottomToTopReferenceTime() float64
+}
+
+// BlindChannel is a special functional channel for type CHANNEL_TYPE_BLIND
+// containing additionally the level of the slats of blind actuators.
+type BlindChannel interface {
+	ShutterChannel
+	// GetSlatsLevel returns the level of the slats between 0.0 (open) and 1.0 (closed)
+	GetSlatsLevel() float64
+	GetPreviousSlatsLevel() float64
+}
+
+// DimmerChannel is a special functional channel for type CHANNEL_TYPE_DIMMER
+// containing the switch state and the dim level of dimming devices.
+type DimmerChannel interface {
+	FunctionalChannel
+	Switchable
+	Dimmable
+	isDimmerChannel()
+}
+
+// NotificationLightChannel is a special functional channel for type CHANNEL_TYPE_NOTIFICATION_LIGHT
+// containing the switch state, the dim level and the color of notification lights.
+type NotificationLightChannel interface {
+	FunctionalChannel
+	Switchable
+	Dimmable
+	// GetSimpleRGBColorState returns one of the RGB_COLOR_STATE constants
+	GetSimpleRGBColorState() string
+	isNotificationLightChannel()
+}
+
+// ContactChannel is a special functional channel for the types CHANNEL_TYPE_SHUTTER_CONTACT,
+// CHANNEL_TYPE_ROTARY_HANDLE and CHANNEL_TYPE_CONTACT_INTERFACE containing the state
+// of windows and doors reported by contacts and handle sensors.
+type ContactChannel interface {
+	FunctionalChannel
+	Openable
+	// GetEventDelay returns the delay in seconds before a change of the window state is reported
+	GetEventDelay() float64
+	HasSabotage() bool
+}
+
+// MotionDetectionChannel is a special functional channel for type CHANNEL_TYPE_MOTION_DETECTION
+// containing the detected motion and the illumination measured by motion detectors.
+type MotionDetectionChannel interface {
+	FunctionalChannel
+	IlluminationMeasuring
+	IsMotionDetected() bool
+	// GetMotionDetectionSendInterval returns one of the MOTION_DETECTION_SEND_INTERVAL constants
+	GetMotionDetectionSendInterval() string
+}
+
+// PresenceDetectionChannel is a special functional channel for type CHANNEL_TYPE_PRESENCE_DETECTION
+// containing the detected presence and the illumination measured by presence sensors.
+type PresenceDetectionChannel interface {
+	FunctionalChannel
+	IlluminationMeasuring
+	IsPresenceDetected() bool
+	// GetMotionDetectionSendInterval returns one of the MOTION_DETECTION_SEND_INTERVAL constants
+	GetMotionDetectionSendInterval() string
+}
+
 // ======================================================
 
 // Stateful is a capability implemented by all interfaces representing data
@@ -175,12 +617,38 @@ type Typed interface {
 	GetType() string
 }
 
+// Raw is a capability implemented by all interfaces representing data
+// decoded from the HomematicIP Cloud. It gives access to the original JSON
+// including attributes not (yet) supported by this library. GetAttribute
+// takes a path with keys separated by dots (e.g. "functionalChannels.1.valvePosition")
+// and returns nil if the path does not exist. The raw JSON is decoded once on
+// the first call, so returned maps and slices are shared and must not be modified.
+type Raw interface {
+	GetRaw() json.RawMessage
+	GetAttribute(path string) any
+}
+
 // Switchable is a capability implemented by all interfaces representing data
 // which contains a switch status.
 type Switchable interface {
 	IsSwitchedOn() bool
 }
 
+// Openable is a capability implemented by all interfaces representing data
+// which contains the state of a window or door.
+type Openable interface {
+	// GetWindowState returns one of the WINDOW_STATE constants
+	GetWindowState() string
+	// IsOpen reports whether the window state is WINDOW_STATE_OPEN or WINDOW_STATE_TILTED
+	IsOpen() bool
+}
+
+// Dimmable is a capability implemented by all interfaces representing data
+// which contains a dim level between 0.0 (off) and 1.0 (full brightness).
+type Dimmable interface {
+	GetDimLevel() float64
+}
+
 // PowerConsumptionMeasuring is a capability implemented by all interfaces
 // representing data which contains a current power consumption.
 type PowerConsumptionMeasuring interface {
@@ -195,12 +663,32 @@ type ClimateMeasuring interface {
 	GetVapourAmount() float64
 }
 
+// IlluminationMeasuring is a capability implemented by all interfaces representing data
+// which contains the measured illumination in lux.
+type IlluminationMeasuring interface {
+	// GetIllumination returns the average illumination over the last send interval
+	GetIllumination() float64
+	GetCurrentIllumination() float64
+}
+
+// ShutterLevelled is a capability implemented by all interfaces representing data
+// which contains the level of a shutter between 0.0 (open) and 1.0 (closed).
+type ShutterLevelled interface {
+	GetShutterLevel() float64
+	// GetPreviousShutterLevel returns the level before the last movement
+	GetPreviousShutterLevel() float64
+	// IsProcessing reports whether the shutter is moving
+	IsProcessing() bool
+}
+
 // ======================================================
 
 // Event represents an event received by a WebSocket connection.
 // It is extended in special sub interfaces.
 type Event interface {
+	Raw
 	Typed
+	GetDecodeWarnings() []DecodeWarning
 }
 type Events []Event
 
@@ -219,6 +707,13 @@ type GroupChangedEvent interface {
 	GetGroup() Group
 }
 
+// HomeChangedEvent is a special event for type EVENT_TYPE_HOME_CHANGED
+// containing the updated status of the Home.
+type HomeChangedEvent interface {
+	Event
+	GetHome() Home
+}
+
 // Origin represents the origin of an event received by a WebSocket connection.
 type Origin interface {
 	Typed